@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/patrickdappollonio/wait-for/wait"
+	"github.com/spf13/viper"
+)
+
+// hostEntry is a host as defined in the configuration file. It can be
+// provided either as a plain string with the host URL, or as an object
+// with per-host settings.
+type hostEntry struct {
+	URL            hostURL        `mapstructure:"url"`
+	Name           string         `mapstructure:"name"`
+	Timeout        time.Duration  `mapstructure:"timeout"`
+	Every          time.Duration  `mapstructure:"every"`
+	AttemptTimeout time.Duration  `mapstructure:"attempt_timeout"`
+	DependsOn      []string       `mapstructure:"depends_on"`
+	Optional       bool           `mapstructure:"optional"`
+	Retry          string         `mapstructure:"retry"`
+	MaxEvery       time.Duration  `mapstructure:"max_every"`
+	Threshold      int            `mapstructure:"success_threshold"`
+	StableFor      time.Duration  `mapstructure:"stable_for"`
+	Options        map[string]any `mapstructure:"options"`
+}
+
+// hostURL is the URL of a host in the configuration file, a distinct type
+// so references to environment variables in it are escaped.
+type hostURL string
+
+// stringToHostEntryHook converts hosts provided as plain strings into a
+// hostEntry, keeping backward compatibility with the list-of-strings form.
+func stringToHostEntryHook(from reflect.Type, to reflect.Type, data any) (any, error) {
+	if from.Kind() != reflect.String || to != reflect.TypeOf(hostEntry{}) {
+		return data, nil
+	}
+
+	return hostEntry{URL: hostURL(data.(string))}, nil
+}
+
+// targetsFromConfig reads the hosts defined under the given key in the
+// configuration file and converts them to targets.
+func targetsFromConfig(v *viper.Viper, key string) ([]wait.Target, error) {
+	var entries []hostEntry
+
+	hook := viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		expandEnvHook,
+		stringToHostEntryHook,
+		mapstructure.StringToTimeDurationHookFunc(),
+	))
+
+	if err := v.UnmarshalKey(key, &entries, hook, func(dc *mapstructure.DecoderConfig) {
+		dc.ErrorUnused = true
+	}); err != nil {
+		return nil, fmt.Errorf("unable to read %q from config file: %w", key, err)
+	}
+
+	targets := make([]wait.Target, 0, len(entries))
+	for i, e := range entries {
+		if e.URL == "" {
+			return nil, fmt.Errorf("host #%d in config file has no url", i+1)
+		}
+
+		options, err := optionValues(e.Options)
+		if err != nil {
+			return nil, fmt.Errorf("host %q in config file: %w", wait.RedactURL(string(e.URL)), err)
+		}
+
+		targets = append(targets, wait.Target{
+			URL:            string(e.URL),
+			Name:           e.Name,
+			Timeout:        e.Timeout,
+			Every:          e.Every,
+			AttemptTimeout: e.AttemptTimeout,
+			DependsOn:      e.DependsOn,
+			Optional:       e.Optional,
+			Retry:          e.Retry,
+			MaxEvery:       e.MaxEvery,
+
+			SuccessThreshold: e.Threshold,
+			StableFor:        e.StableFor,
+
+			Options: options,
+		})
+	}
+
+	return targets, nil
+}
+
+// groupEntry is a group of hosts as defined in the configuration file.
+type groupEntry struct {
+	Name    string   `mapstructure:"name"`
+	Members []string `mapstructure:"members"`
+	Mode    string   `mapstructure:"mode"`
+	AtLeast int      `mapstructure:"at_least"`
+}
+
+// groupsFromConfig reads the groups defined under the given key in the
+// configuration file.
+func groupsFromConfig(v *viper.Viper, key string) ([]wait.Group, error) {
+	var entries []groupEntry
+
+	hook := viper.DecodeHook(expandEnvHook)
+	if err := v.UnmarshalKey(key, &entries, hook, func(dc *mapstructure.DecoderConfig) {
+		dc.ErrorUnused = true
+	}); err != nil {
+		return nil, fmt.Errorf("unable to read %q from config file: %w", key, err)
+	}
+
+	groups := make([]wait.Group, 0, len(entries))
+	for _, e := range entries {
+		groups = append(groups, wait.Group{
+			Name:    e.Name,
+			Members: e.Members,
+			Mode:    e.Mode,
+			AtLeast: e.AtLeast,
+		})
+	}
+
+	return groups, nil
+}
+
+// optionValues converts the probe-specific options from the configuration
+// file into URL values. Each option can be a scalar or a list of scalars.
+func optionValues(options map[string]any) (url.Values, error) {
+	values := make(url.Values, len(options))
+
+	keys := make([]string, 0, len(options))
+	for k := range options {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		switch v := options[k].(type) {
+		case []any:
+			for _, item := range v {
+				s, err := optionString(item)
+				if err != nil {
+					return nil, fmt.Errorf("option %q: %w", k, err)
+				}
+				values.Add(k, s)
+			}
+		default:
+			s, err := optionString(v)
+			if err != nil {
+				return nil, fmt.Errorf("option %q: %w", k, err)
+			}
+			values.Add(k, s)
+		}
+	}
+
+	return values, nil
+}
+
+// optionString converts a single option value to a string, expanding
+// references to environment variables.
+func optionString(v any) (string, error) {
+	s, err := scalarString(v)
+	if err != nil {
+		return "", err
+	}
+
+	return expandEnv(s)
+}
+
+// scalarString converts a scalar value from the configuration file to
+// its string representation.
+func scalarString(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int, int64, uint64, float64:
+		return fmt.Sprint(v), nil
+	default:
+		return "", fmt.Errorf("unsupported value of type %T: must be a string, number or boolean", v)
+	}
+}
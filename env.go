@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// envReference matches references to environment variables, such as
+// "${DB_PASSWORD}". References without braces, like "$DB_PASSWORD", are
+// not expanded, since "$" is common in passwords.
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// fileEnvSuffix is the suffix of the environment variable holding the path
+// to a file with the value of a variable that is not set, following the
+// convention used by most Docker images for secrets.
+const fileEnvSuffix = "_FILE"
+
+// expandEnv replaces every reference to an environment variable in s with
+// its value. If the variable is not set, the contents of the file whose
+// path is set in the same variable suffixed with "_FILE" are used instead,
+// without trailing newlines. Referencing a variable that is not set in
+// either way is an error.
+func expandEnv(s string) (string, error) {
+	return expandEnvEscaped(s, nil)
+}
+
+// expandHostEnv is like expandEnv, but for host URLs: values referenced in
+// the userinfo, path, query or fragment are percent-escaped, so a password
+// containing characters such as "#", "@" or "/" is kept as a whole instead
+// of changing how the URL is parsed. Values referenced in the scheme or the
+// host and port are kept as is, so a variable can still hold a hostname or
+// a whole URL.
+func expandHostEnv(s string) (string, error) {
+	// Find where the userinfo and the host and port end in the host as
+	// written, so the values don't change where each part is found
+	authStart := 0
+	if i := strings.Index(s, "://"); i >= 0 {
+		authStart = i + len("://")
+	}
+
+	authEnd := len(s)
+	if i := strings.IndexAny(s[authStart:], "/?#"); i >= 0 {
+		authEnd = authStart + i
+	}
+
+	userinfoEnd := authStart
+	if i := strings.LastIndex(s[authStart:authEnd], "@"); i >= 0 {
+		userinfoEnd = authStart + i
+	}
+
+	return expandEnvEscaped(s, func(value string, at int) string {
+		switch {
+		case at < authStart:
+			return value
+		case at < userinfoEnd:
+			return escapeEnvValue(value, "")
+		case at < authEnd:
+			return value
+		default:
+			return escapeEnvValue(value, "/")
+		}
+	})
+}
+
+// expandEnvEscaped replaces every reference to an environment variable in
+// s with its value, passed through escape, if set, along with the position
+// of the reference in s.
+func expandEnvEscaped(s string, escape func(value string, at int) string) (string, error) {
+	var b strings.Builder
+	last := 0
+
+	for _, m := range envReference.FindAllStringSubmatchIndex(s, -1) {
+		value, err := lookupEnv(s[m[2]:m[3]])
+		if err != nil {
+			return "", err
+		}
+
+		if escape != nil {
+			value = escape(value, m[0])
+		}
+
+		b.WriteString(s[last:m[0]])
+		b.WriteString(value)
+		last = m[1]
+	}
+
+	b.WriteString(s[last:])
+	return b.String(), nil
+}
+
+// escapeEnvValue percent-escapes every byte of s other than the unreserved
+// characters of RFC 3986 and the ones in keep.
+func escapeEnvValue(s, keep string) string {
+	const hex = "0123456789ABCDEF"
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			c == '-', c == '.', c == '_', c == '~', strings.IndexByte(keep, c) >= 0:
+			b.WriteByte(c)
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&0x0F])
+		}
+	}
+
+	return b.String()
+}
+
+// lookupEnv returns the value of the environment variable, falling back to
+// the contents of the file referenced by its "_FILE" variant.
+func lookupEnv(name string) (string, error) {
+	if value, ok := os.LookupEnv(name); ok {
+		return value, nil
+	}
+
+	path, ok := os.LookupEnv(name + fileEnvSuffix)
+	if !ok {
+		return "", fmt.Errorf("environment variable %q is not set, nor is %q", name, name+fileEnvSuffix)
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("unable to read the value of %q from %s: %w", name, name+fileEnvSuffix, err)
+	}
+
+	return strings.TrimRight(string(contents), "\r\n"), nil
+}
+
+// expandEnvHook expands references to environment variables in the
+// strings read from the configuration file, escaping the values in host
+// URLs as done by expandHostEnv. Probe options, decoded as untyped values,
+// are expanded by optionValues instead, so they aren't expanded twice.
+func expandEnvHook(from reflect.Type, to reflect.Type, data any) (any, error) {
+	if from.Kind() != reflect.String || to.Kind() == reflect.Interface {
+		return data, nil
+	}
+
+	// Hosts provided as plain strings are decoded into a hostEntry
+	if to == reflect.TypeOf(hostURL("")) || to == reflect.TypeOf(hostEntry{}) {
+		return expandHostEnv(data.(string))
+	}
+
+	return expandEnv(data.(string))
+}
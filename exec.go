@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// commandAfterDash returns the command and its arguments provided after a
+// "--" separator. It returns nil if no command was provided, and an error
+// if positional arguments were provided without the separator.
+func commandAfterDash(cmd *cobra.Command, args []string) ([]string, error) {
+	dash := cmd.ArgsLenAtDash()
+
+	if dash < 0 {
+		if len(args) > 0 {
+			return nil, fmt.Errorf("unexpected arguments %q: use \"--\" to separate the command to run", args)
+		}
+
+		return nil, nil
+	}
+
+	if dash > 0 {
+		return nil, fmt.Errorf("unexpected arguments %q before \"--\"", args[:dash])
+	}
+
+	if len(args) == 0 {
+		return nil, nil
+	}
+
+	return args, nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestCommandAfterDash(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "No command",
+			args: []string{"-s", "localhost:80"},
+			want: nil,
+		},
+		{
+			name: "Command after dash",
+			args: []string{"-s", "localhost:80", "--", "./server", "--port", "8080"},
+			want: []string{"./server", "--port", "8080"},
+		},
+		{
+			name: "Dash with no command",
+			args: []string{"-s", "localhost:80", "--"},
+			want: nil,
+		},
+		{
+			name:    "Arguments without dash",
+			args:    []string{"-s", "localhost:80", "./server"},
+			wantErr: true,
+		},
+		{
+			name:    "Arguments before dash",
+			args:    []string{"./server", "--", "foo"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			var gotErr error
+
+			cmd := &cobra.Command{
+				Use: "test",
+				RunE: func(cmd *cobra.Command, args []string) error {
+					got, gotErr = commandAfterDash(cmd, args)
+					return nil
+				},
+			}
+			cmd.Flags().StringSliceP("host", "s", nil, "")
+			cmd.SetArgs(tt.args)
+
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			if (gotErr != nil) != tt.wantErr {
+				t.Fatalf("commandAfterDash() error = %v, wantErr %v", gotErr, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("commandAfterDash() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// execCommand replaces the current process with the given command. The
+// arguments are passed unchanged, including the program name in args[0],
+// and the environment is inherited. On success, this function never returns.
+func execCommand(args []string) error {
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		return fmt.Errorf("unable to find command %q: %w", args[0], err)
+	}
+
+	if err := syscall.Exec(path, args, os.Environ()); err != nil {
+		return fmt.Errorf("unable to execute command %q: %w", args[0], err)
+	}
+
+	return nil
+}
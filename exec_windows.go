@@ -0,0 +1,33 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// execCommand runs the given command and exits with its exit code once it
+// finishes. Windows has no equivalent to replacing the current process, so
+// the command runs as a child process with the standard streams attached.
+// On success, this function never returns.
+func execCommand(args []string) error {
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.ExitCode())
+		}
+
+		return fmt.Errorf("unable to execute command %q: %w", args[0], err)
+	}
+
+	os.Exit(0)
+	return nil
+}
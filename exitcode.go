@@ -0,0 +1,43 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/patrickdappollonio/wait-for/wait"
+	"github.com/patrickdappollonio/wait-for/wait/probes"
+)
+
+// Exit codes returned by the application, so callers can tell apart why
+// the hosts could not be awaited. Remember to update the README when
+// changing these.
+const (
+	exitSuccess     = 0   // all hosts reached the expected state
+	exitFailure     = 1   // any other failure
+	exitConfig      = 2   // invalid flags, configuration file or hosts
+	exitTimeout     = 3   // the timeout was reached before all hosts were up
+	exitAuth        = 4   // a host rejected the provided credentials
+	exitPermanent   = 5   // a host failed with an error retrying won't fix
+	exitInterrupted = 130 // the user requested an early termination
+)
+
+// exitCode returns the exit code for the error returned by the command.
+func exitCode(err error) int {
+	var cfgErr *wait.ConfigError
+
+	switch {
+	case err == nil:
+		return exitSuccess
+	case errors.As(err, &cfgErr):
+		return exitConfig
+	case errors.Is(err, wait.ErrInterrupted):
+		return exitInterrupted
+	case errors.Is(err, probes.ErrAuthentication):
+		return exitAuth
+	case probes.IsPermanent(err):
+		return exitPermanent
+	case errors.Is(err, wait.ErrTimeout):
+		return exitTimeout
+	default:
+		return exitFailure
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/patrickdappollonio/wait-for/wait"
+	"github.com/patrickdappollonio/wait-for/wait/probes"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{
+			name: "No error",
+			err:  nil,
+			want: exitSuccess,
+		},
+		{
+			name: "Unknown error",
+			err:  errors.New("something went wrong"),
+			want: exitFailure,
+		},
+		{
+			name: "Config error",
+			err:  &wait.ConfigError{Err: errors.New("no hosts specified")},
+			want: exitConfig,
+		},
+		{
+			name: "Timeout",
+			err:  fmt.Errorf("10s %w before all hosts were up", wait.ErrTimeout),
+			want: exitTimeout,
+		},
+		{
+			name: "Interrupted",
+			err:  wait.ErrInterrupted,
+			want: exitInterrupted,
+		},
+		{
+			name: "Authentication failure",
+			err:  fmt.Errorf("unable to wait for %q: %w", "mysql://db", fmt.Errorf("%w: access denied", probes.ErrAuthentication)),
+			want: exitAuth,
+		},
+		{
+			name: "Permanent failure",
+			err:  fmt.Errorf("unable to wait for %q: %w", "tcp://nope.invalid:80", probes.Permanent(errors.New("no such host"))),
+			want: exitPermanent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -8,6 +8,6 @@ import (
 func main() {
 	if err := root().Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, "Error:", err.Error())
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
@@ -0,0 +1,122 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// checkDependencies ensures every dependency refers to a named host or
+// group and that there are no cycles between hosts and groups, so no host
+// waits forever. A group waits for its members, so a host can't depend on
+// a group it belongs to.
+func checkDependencies(hostItems []matchedURLItem, groupItems []groupItem) error {
+	edges := groupEdges(groupItems)
+	for _, h := range hostItems {
+		if h.Name != "" {
+			edges[h.Name] = h.DependsOn
+		}
+	}
+
+	for _, h := range hostItems {
+		for _, dep := range h.DependsOn {
+			if _, ok := edges[dep]; !ok {
+				return fmt.Errorf("host %q depends on %q, but there's no host or group with that name", h.String(), dep)
+			}
+		}
+	}
+
+	// Walk the dependencies depth-first, keeping the path being visited to
+	// detect and report cycles.
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[string]int, len(edges))
+	var path []string
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			start := 0
+			for path[start] != name {
+				start++
+			}
+
+			cycle := append(slices.Clone(path[start:]), name)
+			return fmt.Errorf("dependency cycle between hosts and groups: %s", strings.Join(cycle, " -> "))
+		case visited:
+			return nil
+		}
+
+		state[name] = visiting
+		path = append(path, name)
+
+		for _, dep := range edges[name] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+
+	for _, h := range hostItems {
+		if h.Name == "" {
+			continue
+		}
+
+		if err := visit(h.Name); err != nil {
+			return err
+		}
+	}
+
+	for _, g := range groupItems {
+		if err := visit(g.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// waitForDependencies blocks until every dependency of the host reached its
+// expected state, as signaled by closing its channel in done, which for
+// groups happens once they're satisfied.
+func (app *App) waitForDependencies(ctx, sigterm context.Context, h matchedURLItem, done map[string]chan struct{}, startTime time.Time) error {
+	for _, dep := range h.DependsOn {
+		select {
+		case <-done[dep]:
+		case <-sigterm.Done():
+			return ErrInterrupted
+		case <-ctx.Done():
+			if sigterm.Err() != nil {
+				return ErrInterrupted
+			}
+
+			if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return ctx.Err()
+			}
+
+			app.Reporter.Report(Event{
+				Type:     EventTimeout,
+				Time:     time.Now(),
+				HostInfo: h.info(),
+				Elapsed:  time.Since(startTime),
+				Error:    fmt.Sprintf("dependency %q not ready", dep),
+			})
+
+			return fmt.Errorf("%s %w while waiting for dependency %q of %q", h.Timeout, ErrTimeout, dep, h.String())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,180 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/patrickdappollonio/wait-for/wait/probes"
+)
+
+func TestCheckDependencies(t *testing.T) {
+	host := func(name string, deps ...string) matchedURLItem {
+		return matchedURLItem{Raw: "tcp://" + name, Name: name, DependsOn: deps}
+	}
+
+	tests := []struct {
+		name    string
+		hosts   []matchedURLItem
+		groups  []groupItem
+		wantErr string
+	}{
+		{
+			name:  "No dependencies",
+			hosts: []matchedURLItem{host("db"), host("api"), {Raw: "tcp://unnamed"}},
+		},
+		{
+			name:  "Chain and diamond",
+			hosts: []matchedURLItem{host("api", "migrator", "cache"), host("migrator", "db"), host("cache", "db"), host("db")},
+		},
+		{
+			name:   "Dependency on a group",
+			hosts:  []matchedURLItem{host("api", "brokers"), host("kafka-1"), host("kafka-2")},
+			groups: []groupItem{{Name: "brokers", Members: []string{"kafka-1", "kafka-2"}}},
+		},
+		{
+			name:    "Dependency on own group",
+			hosts:   []matchedURLItem{host("kafka-1", "brokers"), host("kafka-2")},
+			groups:  []groupItem{{Name: "brokers", Members: []string{"kafka-1", "kafka-2"}}},
+			wantErr: "dependency cycle between hosts and groups: kafka-1 -> brokers -> kafka-1",
+		},
+		{
+			name:    "Nested groups cycle",
+			hosts:   []matchedURLItem{host("db")},
+			groups:  []groupItem{{Name: "a", Members: []string{"b"}}, {Name: "b", Members: []string{"db", "a"}}},
+			wantErr: "dependency cycle between hosts and groups: a -> b -> a",
+		},
+		{
+			name:    "Unknown dependency",
+			hosts:   []matchedURLItem{host("api", "db")},
+			wantErr: `host "api" depends on "db", but there's no host or group with that name`,
+		},
+		{
+			name:    "Self dependency",
+			hosts:   []matchedURLItem{host("db", "db")},
+			wantErr: "dependency cycle between hosts and groups: db -> db",
+		},
+		{
+			name:    "Cycle",
+			hosts:   []matchedURLItem{host("api", "migrator"), host("migrator", "db"), host("db", "migrator")},
+			wantErr: "dependency cycle between hosts and groups: migrator -> db -> migrator",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkDependencies(tt.hosts, tt.groups)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("checkDependencies() error = %v, want nil", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Fatalf("checkDependencies() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// recordingPinger records every ping in a shared log before returning the
+// configured errors in order, repeating the last one.
+type recordingPinger struct {
+	name string
+	errs []error
+
+	mu  *sync.Mutex
+	log *[]string
+	i   int
+}
+
+func (r *recordingPinger) Bootstrap(string, probes.Options) error { return nil }
+func (r *recordingPinger) Ping(context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	*r.log = append(*r.log, r.name)
+	err := r.errs[min(r.i, len(r.errs)-1)]
+	r.i++
+	return err
+}
+
+func TestAppRunDependencies(t *testing.T) {
+	var (
+		mu  sync.Mutex
+		log []string
+	)
+
+	refused := errors.New("connection refused")
+	pingerRegistry["db"] = func() Pinger {
+		return &recordingPinger{name: "db", errs: []error{refused, refused, nil}, mu: &mu, log: &log}
+	}
+	pingerRegistry["api"] = func() Pinger {
+		return &recordingPinger{name: "api", errs: []error{nil}, mu: &mu, log: &log}
+	}
+	defer delete(pingerRegistry, "db")
+	defer delete(pingerRegistry, "api")
+
+	app := &App{
+		Targets: []Target{
+			{URL: "api://localhost", Name: "api", DependsOn: []string{"db"}},
+			{URL: "db://localhost", Name: "db"},
+		},
+		Timeout:  time.Second,
+		Every:    10 * time.Millisecond,
+		Reporter: NewTextReporter(io.Discard, false),
+	}
+
+	if err := app.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	want := []string{"db", "db", "db", "api"}
+	if len(log) != len(want) {
+		t.Fatalf("pings = %v, want %v", log, want)
+	}
+
+	for i := range want {
+		if log[i] != want[i] {
+			t.Fatalf("pings = %v, want %v", log, want)
+		}
+	}
+
+	// The dependency never comes up, so the dependent host times out too.
+	pingerRegistry["db"] = func() Pinger { return &fakePinger{err: refused} }
+
+	app = &App{
+		Targets: []Target{
+			{URL: "api://localhost", Name: "api", DependsOn: []string{"db"}, Timeout: 50 * time.Millisecond},
+			{URL: "db://localhost", Name: "db"},
+		},
+		Timeout:  time.Second,
+		Every:    10 * time.Millisecond,
+		Reporter: NewTextReporter(io.Discard, false),
+	}
+
+	start := time.Now()
+	if err := app.Run(); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Run() error = %v, want ErrTimeout", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Run() took %s, want the dependent host's timeout to end the run", elapsed)
+	}
+
+	// Cycles are rejected before any host is pinged.
+	app = &App{
+		Targets: []Target{
+			{URL: "api://localhost", Name: "api", DependsOn: []string{"db"}},
+			{URL: "db://localhost", Name: "db", DependsOn: []string{"api"}},
+		},
+		Timeout:  time.Second,
+		Reporter: NewTextReporter(io.Discard, false),
+	}
+
+	var cfgErr *ConfigError
+	if err := app.Run(); !errors.As(err, &cfgErr) {
+		t.Errorf("Run() error = %v, want a *ConfigError", err)
+	}
+}
@@ -0,0 +1,29 @@
+package wait
+
+import "errors"
+
+var (
+	// ErrTimeout is returned when the timeout is reached before all hosts
+	// were up.
+	ErrTimeout = errors.New("timeout reached")
+
+	// ErrInterrupted is returned when the user requested an early
+	// termination, such as by pressing Ctrl-C.
+	ErrInterrupted = errors.New("user requested early termination")
+)
+
+// ConfigError is returned when the settings or any of the hosts are
+// invalid, before any host is pinged.
+type ConfigError struct {
+	Err error
+}
+
+// Error returns the message of the underlying error.
+func (e *ConfigError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,325 @@
+package wait
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Values accepted by Group.Mode, defining how many members of a group must
+// reach their expected state for the group to be satisfied.
+const (
+	GroupAll     = "all"
+	GroupAny     = "any"
+	GroupAtLeast = "at_least"
+)
+
+// Group is a named set of hosts or other groups that is satisfied once
+// enough of its members reach their expected state, such as any replica of
+// a database or a quorum of brokers. Members of a group are no longer
+// required on their own: the run succeeds once every group not nested in
+// another one is satisfied, along with every host not in a group.
+type Group struct {
+	// Name identifies the group in the output, in other groups and in the
+	// dependencies of hosts.
+	Name string
+
+	// Members holds the names of the hosts and groups in this group.
+	Members []string
+
+	// Mode is either GroupAll, which is the default, GroupAny or
+	// GroupAtLeast. It defaults to GroupAtLeast if AtLeast is set.
+	Mode string
+
+	// AtLeast is the number of members that must reach their expected
+	// state when Mode is GroupAtLeast.
+	AtLeast int
+}
+
+// groupItem is a group with its settings validated, along with the number
+// of members required to satisfy it.
+type groupItem struct {
+	Name     string
+	Members  []string
+	Mode     string
+	Required int
+}
+
+// info returns the description of the group used in events.
+func (g *groupItem) info() *GroupInfo {
+	return &GroupInfo{Name: g.Name, Mode: g.Mode, Required: g.Required, Members: g.Members}
+}
+
+// prepareGroups validates the groups, ensuring names are unique among
+// hosts and groups, and that every member refers to an existing host or
+// group. Cycles between groups are detected by checkDependencies.
+func (app *App) prepareGroups(hostItems []matchedURLItem) ([]groupItem, error) {
+	names := make(map[string]bool, len(hostItems)+len(app.Groups))
+	for _, h := range hostItems {
+		if h.Name != "" {
+			names[h.Name] = true
+		}
+	}
+
+	for _, g := range app.Groups {
+		if g.Name == "" {
+			return nil, fmt.Errorf("groups must have a name")
+		}
+
+		if names[g.Name] {
+			return nil, fmt.Errorf("duplicated name %q: hosts and groups must have unique names", g.Name)
+		}
+
+		names[g.Name] = true
+	}
+
+	groupItems := make([]groupItem, 0, len(app.Groups))
+	for _, g := range app.Groups {
+		if len(g.Members) == 0 {
+			return nil, fmt.Errorf("group %q has no members", g.Name)
+		}
+
+		for _, m := range g.Members {
+			if !names[m] {
+				return nil, fmt.Errorf("group %q has member %q, but there's no host or group with that name", g.Name, m)
+			}
+		}
+
+		mode := g.Mode
+		if mode == "" {
+			mode = GroupAll
+			if g.AtLeast != 0 {
+				mode = GroupAtLeast
+			}
+		}
+
+		var required int
+		switch mode {
+		case GroupAll:
+			required = len(g.Members)
+		case GroupAny:
+			required = 1
+		case GroupAtLeast:
+			if g.AtLeast < 1 || g.AtLeast > len(g.Members) {
+				return nil, fmt.Errorf("invalid value for at least in group %q: %d (must be between 1 and the number of members, %d)", g.Name, g.AtLeast, len(g.Members))
+			}
+
+			required = g.AtLeast
+		default:
+			return nil, fmt.Errorf("invalid mode for group %q: %q (must be %q, %q or %q)", g.Name, mode, GroupAll, GroupAny, GroupAtLeast)
+		}
+
+		if g.AtLeast != 0 && mode != GroupAtLeast {
+			return nil, fmt.Errorf("group %q sets at least, which is only supported with mode %q", g.Name, GroupAtLeast)
+		}
+
+		groupItems = append(groupItems, groupItem{Name: g.Name, Members: g.Members, Mode: mode, Required: required})
+	}
+
+	return groupItems, nil
+}
+
+// hostStatus is the state of a host or group while waiting.
+type hostStatus int
+
+const (
+	statusPending hostStatus = iota
+	statusReached
+	statusFailed
+)
+
+// tracker keeps the state of every host and group, to tell when the run is
+// done and whether it succeeded.
+type tracker struct {
+	hosts  []matchedURLItem
+	groups []groupItem
+
+	status   []hostStatus
+	errs     []error
+	progress []*progress
+
+	hostsByName  map[string]int
+	groupsByName map[string]*groupItem
+	grouped      map[string]bool
+}
+
+// newTracker creates a tracker with every host pending.
+func newTracker(hostItems []matchedURLItem, groupItems []groupItem) *tracker {
+	t := &tracker{
+		hosts:        hostItems,
+		groups:       groupItems,
+		status:       make([]hostStatus, len(hostItems)),
+		errs:         make([]error, len(hostItems)),
+		progress:     make([]*progress, len(hostItems)),
+		hostsByName:  make(map[string]int, len(hostItems)),
+		groupsByName: make(map[string]*groupItem, len(groupItems)),
+		grouped:      make(map[string]bool),
+	}
+
+	for i, h := range hostItems {
+		t.progress[i] = &progress{}
+		if h.Name != "" {
+			t.hostsByName[h.Name] = i
+		}
+	}
+
+	for i, g := range groupItems {
+		t.groupsByName[g.Name] = &groupItems[i]
+		for _, m := range g.Members {
+			t.grouped[m] = true
+		}
+	}
+
+	return t
+}
+
+// finish records the outcome of the host at index i, which reached its
+// expected state if err is nil.
+func (t *tracker) finish(i int, err error) {
+	t.status[i] = statusReached
+	if err != nil {
+		t.status[i] = statusFailed
+		t.errs[i] = err
+	}
+}
+
+// statusOf returns the state of the named host or group. A group is failed
+// once too many of its members failed for it to be satisfied.
+func (t *tracker) statusOf(name string) hostStatus {
+	if i, ok := t.hostsByName[name]; ok {
+		return t.status[i]
+	}
+
+	g := t.groupsByName[name]
+	reached, failed := 0, 0
+	for _, m := range g.Members {
+		switch t.statusOf(m) {
+		case statusReached:
+			reached++
+		case statusFailed:
+			failed++
+		}
+	}
+
+	switch {
+	case reached >= g.Required:
+		return statusReached
+	case len(g.Members)-failed < g.Required:
+		return statusFailed
+	default:
+		return statusPending
+	}
+}
+
+// result reports whether the run is done: once a required host or group
+// fails, or once all of them reached their expected state. Hosts and groups
+// are required unless they're optional or members of a group. If nothing is
+// required, the run is done once every host finished.
+func (t *tracker) result() (bool, error) {
+	done := true
+	required := false
+
+	for i, h := range t.hosts {
+		if h.Optional || (h.Name != "" && t.grouped[h.Name]) {
+			continue
+		}
+
+		required = true
+
+		switch t.status[i] {
+		case statusFailed:
+			return true, t.errs[i]
+		case statusPending:
+			done = false
+		}
+	}
+
+	for _, g := range t.groups {
+		if t.grouped[g.Name] {
+			continue
+		}
+
+		required = true
+		switch t.statusOf(g.Name) {
+		case statusFailed:
+			return true, t.groupError(&g)
+		case statusPending:
+			done = false
+		}
+	}
+
+	if !required {
+		return !slices.Contains(t.status, statusPending), nil
+	}
+
+	return done, nil
+}
+
+// optionalDown returns the optional hosts that never reached their
+// expected state, either because they failed or because the run was done
+// before they did.
+func (t *tracker) optionalDown() []string {
+	var hosts []string
+	for i, h := range t.hosts {
+		if h.Optional && t.status[i] != statusReached {
+			hosts = append(hosts, h.String())
+		}
+	}
+
+	return hosts
+}
+
+// groupError returns the error for a group that can't be satisfied,
+// wrapping the error of the first member that failed.
+func (t *tracker) groupError(g *groupItem) error {
+	return fmt.Errorf("group %q can't be satisfied, %s: %w", g.Name, g.info().describe(), t.firstError(g.Name))
+}
+
+// firstError returns the error of the first host that failed within the
+// named host or group.
+func (t *tracker) firstError(name string) error {
+	if i, ok := t.hostsByName[name]; ok {
+		return t.errs[i]
+	}
+
+	for _, m := range t.groupsByName[name].Members {
+		if t.statusOf(m) == statusFailed {
+			return t.firstError(m)
+		}
+	}
+
+	return nil
+}
+
+// satisfiedBy returns the members of the group that reached their expected
+// state.
+func (t *tracker) satisfiedBy(g *groupItem) []string {
+	members := []string{}
+	for _, m := range g.Members {
+		if t.statusOf(m) == statusReached {
+			members = append(members, m)
+		}
+	}
+
+	return members
+}
+
+// groupEdges returns the names each group waits for, used to detect cycles
+// between groups and dependencies.
+func groupEdges(groupItems []groupItem) map[string][]string {
+	edges := make(map[string][]string, len(groupItems))
+	for _, g := range groupItems {
+		edges[g.Name] = slices.Clone(g.Members)
+	}
+
+	return edges
+}
+
+// joinNames returns the names separated by commas, or "none" if empty.
+func joinNames(names []string) string {
+	if len(names) == 0 {
+		return "none"
+	}
+
+	return strings.Join(names, ", ")
+}
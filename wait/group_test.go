@@ -0,0 +1,301 @@
+package wait
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPrepareGroups(t *testing.T) {
+	hosts := []matchedURLItem{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Raw: "tcp://unnamed"}}
+
+	tests := []struct {
+		name    string
+		groups  []Group
+		want    []groupItem
+		wantErr bool
+	}{
+		{
+			name:   "Default mode",
+			groups: []Group{{Name: "all", Members: []string{"a", "b"}}},
+			want:   []groupItem{{Name: "all", Members: []string{"a", "b"}, Mode: GroupAll, Required: 2}},
+		},
+		{
+			name:   "Any",
+			groups: []Group{{Name: "any", Members: []string{"a", "b"}, Mode: GroupAny}},
+			want:   []groupItem{{Name: "any", Members: []string{"a", "b"}, Mode: GroupAny, Required: 1}},
+		},
+		{
+			name:   "At least implied",
+			groups: []Group{{Name: "quorum", Members: []string{"a", "b", "c"}, AtLeast: 2}},
+			want:   []groupItem{{Name: "quorum", Members: []string{"a", "b", "c"}, Mode: GroupAtLeast, Required: 2}},
+		},
+		{
+			name: "Nested",
+			groups: []Group{
+				{Name: "inner", Members: []string{"a", "b"}, Mode: GroupAny},
+				{Name: "outer", Members: []string{"inner", "c"}},
+			},
+			want: []groupItem{
+				{Name: "inner", Members: []string{"a", "b"}, Mode: GroupAny, Required: 1},
+				{Name: "outer", Members: []string{"inner", "c"}, Mode: GroupAll, Required: 2},
+			},
+		},
+		{
+			name:    "No name",
+			groups:  []Group{{Members: []string{"a"}}},
+			wantErr: true,
+		},
+		{
+			name:    "Name used by a host",
+			groups:  []Group{{Name: "a", Members: []string{"b"}}},
+			wantErr: true,
+		},
+		{
+			name:    "No members",
+			groups:  []Group{{Name: "empty"}},
+			wantErr: true,
+		},
+		{
+			name:    "Unknown member",
+			groups:  []Group{{Name: "g", Members: []string{"a", "z"}}},
+			wantErr: true,
+		},
+		{
+			name:    "At least more than members",
+			groups:  []Group{{Name: "g", Members: []string{"a", "b"}, AtLeast: 3}},
+			wantErr: true,
+		},
+		{
+			name:    "At least with another mode",
+			groups:  []Group{{Name: "g", Members: []string{"a", "b"}, Mode: GroupAny, AtLeast: 1}},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid mode",
+			groups:  []Group{{Name: "g", Members: []string{"a", "b"}, Mode: "most"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &App{Groups: tt.groups}
+			got, err := app.prepareGroups(hosts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("prepareGroups() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("prepareGroups() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrackerResult(t *testing.T) {
+	refused := errors.New("connection refused")
+	hosts := []matchedURLItem{{Name: "primary"}, {Name: "replica"}, {Name: "kafka-1"}, {Name: "kafka-2"}, {Name: "kafka-3"}, {Raw: "tcp://cache"}}
+	groups := []groupItem{
+		{Name: "database", Members: []string{"primary", "replica"}, Mode: GroupAny, Required: 1},
+		{Name: "brokers", Members: []string{"kafka-1", "kafka-2", "kafka-3"}, Mode: GroupAtLeast, Required: 2},
+	}
+
+	tests := []struct {
+		name     string
+		results  map[int]error
+		wantDone bool
+		wantErr  bool
+	}{
+		{
+			name:    "Nothing finished",
+			results: map[int]error{},
+		},
+		{
+			name:    "Groups satisfied but ungrouped host pending",
+			results: map[int]error{1: nil, 2: nil, 4: nil},
+		},
+		{
+			name:     "Groups satisfied and ungrouped host up",
+			results:  map[int]error{1: nil, 2: nil, 4: nil, 5: nil},
+			wantDone: true,
+		},
+		{
+			name:    "Members failed without making a group impossible",
+			results: map[int]error{0: refused, 2: refused, 5: nil},
+		},
+		{
+			name:     "Group impossible",
+			results:  map[int]error{2: refused, 3: refused},
+			wantDone: true,
+			wantErr:  true,
+		},
+		{
+			name:     "Ungrouped host failed",
+			results:  map[int]error{5: refused},
+			wantDone: true,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := newTracker(hosts, groups)
+			for i, err := range tt.results {
+				tr.finish(i, err)
+			}
+
+			done, err := tr.result()
+			if done != tt.wantDone || (err != nil) != tt.wantErr {
+				t.Errorf("result() = %v, %v, want %v, error %v", done, err, tt.wantDone, tt.wantErr)
+			}
+
+			if tt.wantErr && !errors.Is(err, refused) {
+				t.Errorf("result() error = %v, want it to wrap the member error", err)
+			}
+		})
+	}
+}
+
+func TestAppRunGroups(t *testing.T) {
+	pingerRegistry["up"] = func() Pinger { return &fakePinger{} }
+	pingerRegistry["refused"] = func() Pinger { return &fakePinger{err: errors.New("connection refused")} }
+	defer delete(pingerRegistry, "up")
+	defer delete(pingerRegistry, "refused")
+
+	targets := []Target{
+		{URL: "up://kafka-1", Name: "kafka-1"},
+		{URL: "refused://kafka-2", Name: "kafka-2", Timeout: 50 * time.Millisecond},
+		{URL: "up://kafka-3", Name: "kafka-3"},
+		{URL: "refused://primary", Name: "primary", Timeout: 50 * time.Millisecond},
+		{URL: "up://replica", Name: "replica"},
+		{URL: "up://api", Name: "api", DependsOn: []string{"backend"}},
+	}
+
+	tests := []struct {
+		name    string
+		groups  []Group
+		wantErr bool
+	}{
+		{
+			name: "Quorum and any",
+			groups: []Group{
+				{Name: "brokers", Members: []string{"kafka-1", "kafka-2", "kafka-3"}, AtLeast: 2},
+				{Name: "database", Members: []string{"primary", "replica"}, Mode: GroupAny},
+				{Name: "backend", Members: []string{"brokers", "database"}},
+			},
+		},
+		{
+			name: "All members required",
+			groups: []Group{
+				{Name: "brokers", Members: []string{"kafka-1", "kafka-2", "kafka-3"}},
+				{Name: "database", Members: []string{"primary", "replica"}, Mode: GroupAny},
+				{Name: "backend", Members: []string{"brokers", "database"}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{}
+			app := &App{Targets: targets, Groups: tt.groups, Timeout: 5 * time.Second, Every: 10 * time.Millisecond, Reporter: rec}
+
+			start := time.Now()
+			err := app.Run()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr && !errors.Is(err, ErrTimeout) {
+				t.Errorf("Run() error = %v, want it to wrap ErrTimeout", err)
+			}
+
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("Run() took %s, want it to end once the groups were resolved", elapsed)
+			}
+
+			var reports []*GroupInfo
+			for _, e := range rec.events {
+				if e.Type == EventGroup {
+					reports = append(reports, e.Group)
+				}
+			}
+
+			if len(reports) != len(tt.groups) {
+				t.Fatalf("got %d group events, want %d", len(reports), len(tt.groups))
+			}
+
+			if got := reports[1].Satisfied; !reflect.DeepEqual(got, []string{"replica"}) {
+				t.Errorf("database satisfied by %v, want [replica]", got)
+			}
+		})
+	}
+}
+
+func TestAppRunOptional(t *testing.T) {
+	pingerRegistry["up"] = func() Pinger { return &fakePinger{} }
+	pingerRegistry["refused"] = func() Pinger { return &fakePinger{err: errors.New("connection refused")} }
+	defer delete(pingerRegistry, "up")
+	defer delete(pingerRegistry, "refused")
+
+	tests := []struct {
+		name             string
+		targets          []Target
+		wantErr          bool
+		wantOptionalDown []string
+	}{
+		{
+			name: "Optional host down",
+			targets: []Target{
+				{URL: "up://api"},
+				{URL: "refused://tracing", Name: "tracing", Optional: true, Timeout: 50 * time.Millisecond},
+			},
+			wantOptionalDown: []string{"tracing"},
+		},
+		{
+			name: "Optional host never awaited",
+			targets: []Target{
+				{URL: "up://api"},
+				{URL: "refused://metrics", Optional: true},
+			},
+			wantOptionalDown: []string{"refused://metrics"},
+		},
+		{
+			name: "Only optional hosts",
+			targets: []Target{
+				{URL: "up://api", Optional: true},
+				{URL: "refused://tracing", Name: "tracing", Optional: true, Timeout: 50 * time.Millisecond},
+			},
+			wantOptionalDown: []string{"tracing"},
+		},
+		{
+			name: "Required host down",
+			targets: []Target{
+				{URL: "refused://api", Timeout: 50 * time.Millisecond},
+				{URL: "up://tracing", Name: "tracing", Optional: true},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{}
+			app := &App{Targets: tt.targets, Timeout: time.Second, Every: 10 * time.Millisecond, Reporter: rec}
+			if err := app.Run(); (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			summary := rec.events[len(rec.events)-1]
+			if summary.Type != EventSummary {
+				t.Fatalf("last event = %s, want summary", summary.Type)
+			}
+
+			if !reflect.DeepEqual(summary.OptionalDown, tt.wantOptionalDown) {
+				t.Errorf("summary optional hosts down = %v, want %v", summary.OptionalDown, tt.wantOptionalDown)
+			}
+		})
+	}
+}
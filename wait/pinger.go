@@ -1,195 +1,638 @@
 package wait
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/patrickdappollonio/wait-for/wait/probes"
-	"golang.org/x/sync/errgroup"
 )
 
-// App represents the application configuration.
+// Values accepted by App.Until, defining which state the hosts are awaited
+// to reach.
+const (
+	UntilUp   = "up"
+	UntilDown = "down"
+)
+
+// downPrefix is the prefix that can be added to any host to wait for it to
+// go down, regardless of App.Until.
+const downPrefix = "!"
+
+// App represents the application configuration. Hosts are plain host
+// strings, while Targets are hosts with their own settings, which override
+// the ones in App for that host only.
 type App struct {
 	Hosts   []string
+	Targets []Target
 	Timeout time.Duration
 	Every   time.Duration
 	Verbose bool
-
-	padding int
+	Until   string
+
+	// AttemptTimeout is the maximum time a single attempt against a host
+	// can take. If zero, probes.DefaultAttemptTimeout is used.
+	AttemptTimeout time.Duration
+
+	// Retry is the strategy used to compute the time to wait between
+	// attempts, using Every as the base interval: RetryConstant, which is
+	// the default, RetryLinear, RetryExponential, RetryFullJitter or
+	// RetryDecorrelatedJitter.
+	Retry string
+
+	// MaxEvery caps the time to wait between attempts for the strategies
+	// that increase it, so it's ignored by RetryConstant. If zero,
+	// DefaultMaxEvery is used, or Every if it's longer.
+	MaxEvery time.Duration
+
+	// RetryAllErrors keeps retrying hosts failing with errors classified as
+	// permanent by their probe, such as a hostname that doesn't exist,
+	// instead of failing right away.
+	RetryAllErrors bool
+
+	// SuccessThreshold is the number of consecutive attempts in which a
+	// host must reach the expected state before it's considered done, to
+	// avoid declaring up a host that crashes right after accepting a
+	// connection. If zero, a single attempt is enough.
+	SuccessThreshold int
+
+	// StableFor is the minimum time a host must stay in the expected
+	// state, measured from the first of its consecutive successful
+	// attempts. If zero, no minimum is enforced.
+	StableFor time.Duration
+
+	// Groups are named sets of hosts, or other groups, satisfied once
+	// enough of their members reach the expected state. Members of a group
+	// are only required through the group.
+	Groups []Group
+
+	// Reporter receives the events emitted while waiting for hosts. If nil,
+	// events are printed as text to the standard output.
+	Reporter Reporter
 }
 
 // Pinger defines the interface for a pinger.
 type Pinger interface {
-	Bootstrap(host string) error
+	Bootstrap(host string, opts probes.Options) error
 	Ping(ctx context.Context) error
 }
 
 // pingerRegistry holds the mapping from protocol to pinger handler.
 // Add your own pinger here.
 var pingerRegistry = map[string]func() Pinger{
-	"tcp":      func() Pinger { return &probes.TCPPinger{} },
-	"udp":      func() Pinger { return &probes.UDPPinger{} },
-	"mysql":    func() Pinger { return &probes.MySQLPinger{} },
-	"postgres": func() Pinger { return &probes.PostgresPinger{} },
-	"http":     func() Pinger { return &probes.HTTPPinger{} },
-	"https":    func() Pinger { return &probes.HTTPSPinger{} },
+	"tcp":         func() Pinger { return &probes.TCPPinger{} },
+	"udp":         func() Pinger { return &probes.UDPPinger{} },
+	"mysql":       func() Pinger { return &probes.MySQLPinger{} },
+	"postgres":    func() Pinger { return &probes.PostgresPinger{} },
+	"http":        func() Pinger { return &probes.HTTPPinger{} },
+	"https":       func() Pinger { return &probes.HTTPSPinger{} },
+	"redis":       func() Pinger { return &probes.RedisPinger{} },
+	"rediss":      func() Pinger { return &probes.RedisPinger{} },
+	"kafka":       func() Pinger { return &probes.KafkaPinger{} },
+	"mongodb":     func() Pinger { return &probes.MongoPinger{} },
+	"mongodb+srv": func() Pinger { return &probes.MongoPinger{} },
+	"grpc":        func() Pinger { return &probes.GRPCPinger{} },
+	"grpcs":       func() Pinger { return &probes.GRPCPinger{} },
+	"amqp":        func() Pinger { return &probes.AMQPPinger{} },
+	"amqps":       func() Pinger { return &probes.AMQPPinger{} },
+	"nats":        func() Pinger { return &probes.NATSPinger{} },
+	"dns":         func() Pinger { return &probes.DNSPinger{} },
 }
 
-// matchedURLItem is a helper struct to hold the URL and the raw string.
+// matchedURLItem is a helper struct to hold the URL and the raw string,
+// along with the settings resolved for this host.
+// When Down is true, the host is awaited until it stops responding.
+// Raw must never be shown to the user as is, since it may contain
+// credentials: use String, info or redact instead.
 type matchedURLItem struct {
-	Raw    string
-	Pinger Pinger
+	Raw     string
+	Scheme  string
+	Pinger  Pinger
+	Params  url.Values
+	Down    bool
+	Name    string
+	Timeout time.Duration
+	Every   time.Duration
+
+	// Retry and MaxEvery define how the time between attempts grows.
+	Retry    string
+	MaxEvery time.Duration
+
+	// DependsOn holds the names of the hosts that must reach their expected
+	// state before this one is pinged.
+	DependsOn []string
+
+	// Optional hosts never fail the run: they're pinged along with the
+	// others, but not waited for once all required hosts are done.
+	Optional bool
+
+	// SuccessThreshold and StableFor define how long the host must stay in
+	// the expected state before it's considered done.
+	SuccessThreshold int
+	StableFor        time.Duration
+
+	// secrets are the credentials found in the host URL and its options,
+	// masked in every rendering of the host.
+	secrets []string
 }
 
-// String returns the string representation of the URL.
+// String returns the string representation of the URL, with credentials
+// masked, or the host name if one was provided.
 func (u *matchedURLItem) String() string {
-	return u.Raw
-}
+	if u.Name != "" {
+		return u.Name
+	}
 
-// stringifyHosts returns a string representation of the hosts, with all
-// the URLs quoted and separated by commas.
-func stringifyHosts(urls []matchedURLItem) string {
-	var sb strings.Builder
+	if u.Down {
+		return downPrefix + RedactURL(u.Raw)
+	}
 
-	for i, v := range urls {
-		if i > 0 {
-			sb.WriteString(", ")
-		}
+	return RedactURL(u.Raw)
+}
 
-		sb.WriteString(`"` + v.String() + `"`)
+// info returns the description of the host used in events.
+func (u *matchedURLItem) info() HostInfo {
+	until := UntilUp
+	if u.Down {
+		until = UntilDown
 	}
 
-	return sb.String()
+	return HostInfo{Host: RedactURL(u.Raw), Name: u.Name, Probe: u.Scheme, Until: until, Optional: u.Optional, DependsOn: u.DependsOn}
 }
 
-// Run executes the application.
+// Run executes the application. Invalid settings or hosts are reported as a
+// *ConfigError, while failures while waiting wrap ErrTimeout, ErrInterrupted
+// or the error of the probe that failed permanently, as reported by
+// probes.IsPermanent.
 func (app *App) Run() error {
-	if len(app.Hosts) == 0 {
-		return fmt.Errorf("no hosts specified")
+	if app.Reporter == nil {
+		app.Reporter = NewTextReporter(os.Stdout, app.Verbose)
+	}
+
+	hostItems, groupItems, err := app.prepare()
+	if err != nil {
+		return &ConfigError{Err: err}
+	}
+
+	// Register signal handlers for early termination.
+	sigterm, done := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer done()
+
+	// Create a context with a timeout for the maximum time allowed, which
+	// is the longest timeout among all hosts.
+	timeout := app.Timeout
+	for _, h := range hostItems {
+		timeout = max(timeout, h.Timeout)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// Document what are we doing to the user.
+	hosts := make([]HostInfo, 0, len(hostItems))
+	for _, h := range hostItems {
+		hosts = append(hosts, h.info())
+	}
+
+	startTime := time.Now()
+	app.Reporter.Report(Event{
+		Type:    EventStart,
+		Time:    startTime,
+		Hosts:   hosts,
+		Timeout: timeout,
+		Every:   app.Every,
+	})
+
+	t := newTracker(hostItems, groupItems)
+	err = app.wait(ctx, sigterm, t, timeout)
+
+	// Report which members satisfied each group.
+	for i := range t.groups {
+		g := &t.groups[i]
+		info := g.info()
+		info.Satisfied = t.satisfiedBy(g)
+
+		app.Reporter.Report(Event{
+			Type:    EventGroup,
+			Time:    time.Now(),
+			Group:   info,
+			Success: t.statusOf(g.Name) == statusReached,
+		})
+	}
+
+	summary := Event{
+		Type:         EventSummary,
+		Time:         time.Now(),
+		Success:      err == nil,
+		Elapsed:      time.Since(startTime),
+		OptionalDown: t.optionalDown(),
 	}
 
-	hostItems := make([]matchedURLItem, 0, len(app.Hosts))
+	if err != nil {
+		summary.Error = err.Error()
+	}
+
+	app.Reporter.Report(summary)
+	return err
+}
+
+// prepare validates the settings, then parses and bootstraps every host,
+// and validates the groups.
+func (app *App) prepare() ([]matchedURLItem, []groupItem, error) {
+	if len(app.Hosts) == 0 && len(app.Targets) == 0 {
+		return nil, nil, fmt.Errorf("no hosts specified")
+	}
+
+	switch app.Until {
+	case "":
+		app.Until = UntilUp
+	case UntilUp, UntilDown:
+	default:
+		return nil, nil, fmt.Errorf("invalid value for until: %q (must be %q or %q)", app.Until, UntilUp, UntilDown)
+	}
+
+	// Hosts provided as plain strings use the global settings.
+	targets := make([]Target, 0, len(app.Targets)+len(app.Hosts))
+	targets = append(targets, app.Targets...)
 	for _, rawURL := range app.Hosts {
+		targets = append(targets, Target{URL: rawURL})
+	}
+
+	names := make(map[string]bool)
+	hostItems := make([]matchedURLItem, 0, len(targets))
+	for _, target := range targets {
+		rawURL := target.URL
+
 		// Parse the host URL
 		matched, err := parseHost(rawURL)
 		if err != nil {
-			return fmt.Errorf("failed to parse host %q: %v", rawURL, err)
+			return nil, nil, fmt.Errorf("failed to parse host %q: %v", RedactURL(rawURL), err)
+		}
+
+		// Ensure host names are unique, so they can be told apart
+		if target.Name != "" {
+			if names[target.Name] {
+				return nil, nil, fmt.Errorf("duplicated host name %q", target.Name)
+			}
+
+			names[target.Name] = true
+		}
+
+		// Apply the per-host settings, falling back to the global ones
+		matched.Name = target.Name
+		matched.Timeout = cmp.Or(target.Timeout, app.Timeout)
+		matched.Every = cmp.Or(target.Every, app.Every)
+		matched.DependsOn = target.DependsOn
+		matched.Optional = target.Optional
+		matched.Retry = cmp.Or(target.Retry, app.Retry, RetryConstant)
+		matched.MaxEvery = cmp.Or(target.MaxEvery, app.MaxEvery)
+
+		if matched.Every <= 0 {
+			return nil, nil, fmt.Errorf("invalid every %s for host %q: must be positive", matched.Every, matched.String())
 		}
 
-		// Calculate the padding for the output
-		if len(rawURL) > app.padding {
-			app.padding = len(matched.Raw)
+		if !slices.Contains(retryStrategies, matched.Retry) {
+			return nil, nil, fmt.Errorf("invalid retry strategy %q for host %q (must be one of: %s)", matched.Retry, matched.String(), strings.Join(retryStrategies, ", "))
 		}
 
+		if matched.Retry != RetryConstant && matched.MaxEvery > 0 && matched.MaxEvery < matched.Every {
+			return nil, nil, fmt.Errorf("invalid max every %s for host %q: must not be shorter than every (%s)", matched.MaxEvery, matched.String(), matched.Every)
+		}
+
+		// Only a maximum set explicitly is rejected for being shorter than
+		// every, the default one gives way to it
+		if matched.MaxEvery == 0 {
+			matched.MaxEvery = max(DefaultMaxEvery, matched.Every)
+		}
+
+		matched.SuccessThreshold = cmp.Or(target.SuccessThreshold, app.SuccessThreshold, 1)
+		matched.StableFor = cmp.Or(target.StableFor, app.StableFor)
+
+		if matched.SuccessThreshold < 1 {
+			return nil, nil, fmt.Errorf("invalid success threshold %d for host %q: must be at least 1", matched.SuccessThreshold, matched.String())
+		}
+
+		if matched.StableFor < 0 {
+			return nil, nil, fmt.Errorf("invalid stable duration %s for host %q: must not be negative", matched.StableFor, matched.String())
+		}
+
+		// Wait for the host to go down if requested globally
+		if app.Until == UntilDown {
+			matched.Down = true
+		}
+
+		// Options from the config file override the ones in the URL fragment
+		for k, v := range target.Options {
+			matched.Params[k] = v
+		}
+
+		matched.addSecrets(secretsFromParams(matched.Params)...)
+
 		// Bootstrap the pinger and validate its URL
-		if err := matched.Pinger.Bootstrap(rawURL); err != nil {
-			return fmt.Errorf("failed to bootstrap host %q: %v", rawURL, err)
+		opts := probes.Options{
+			AttemptTimeout: cmp.Or(target.AttemptTimeout, app.AttemptTimeout, probes.DefaultAttemptTimeout),
+			Params:         matched.Params,
+		}
+
+		if opts.AttemptTimeout <= 0 {
+			return nil, nil, fmt.Errorf("invalid attempt timeout %s for host %q: must be positive", opts.AttemptTimeout, matched.String())
+		}
+
+		if err := matched.Pinger.Bootstrap(matched.Raw, opts); err != nil {
+			return nil, nil, fmt.Errorf("failed to bootstrap host %q: %s", RedactURL(rawURL), matched.redact(err.Error()))
 		}
 
 		// Append the host to the list
 		hostItems = append(hostItems, *matched)
 	}
 
-	// Register signal handlers for early termination.
-	sigterm, done := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	defer done()
+	groupItems, err := app.prepareGroups(hostItems)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := checkDependencies(hostItems, groupItems); err != nil {
+		return nil, nil, err
+	}
 
-	// Create a context with a timeout for the maximum time allowed.
-	ctx, cancel := context.WithTimeout(context.Background(), app.Timeout)
+	return hostItems, groupItems, nil
+}
+
+// hostResult is the outcome of waiting for the host at the given index,
+// which reached its expected state if err is nil.
+type hostResult struct {
+	index int
+	err   error
+}
+
+// wait pings all hosts concurrently until the required hosts and groups
+// reach the expected state, returning the first error that makes this
+// impossible. Hosts with dependencies are only pinged once their
+// dependencies reached their expected state.
+func (app *App) wait(ctx, sigterm context.Context, t *tracker, timeout time.Duration) error {
+	// Wait for the hosts still being pinged to stop before returning, once
+	// canceled, so no event is reported after the run is done.
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	// The hosts are pinged on a context also canceled by a signal, so the
+	// attempts in flight are interrupted instead of running to their end.
+	runCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	// Document what are we doing to the user.
-	fmt.Fprintln(
-		os.Stdout,
-		"Waiting for hosts:", stringifyHosts(hostItems),
-		fmt.Sprintf("(timeout: %s, attempting every %s)", app.Timeout, app.Every),
-	)
+	stop := context.AfterFunc(sigterm, cancel)
+	defer stop()
 
-	// Create an error group.
-	var eg errgroup.Group
+	// Named hosts and groups signal when they reach their expected state,
+	// so the hosts depending on them can start.
+	done := make(map[string]chan struct{}, len(t.hosts)+len(t.groups))
+	for _, host := range t.hosts {
+		if host.Name != "" {
+			done[host.Name] = make(chan struct{})
+		}
+	}
 
-	// Iterate over all hosts and ping them.
-	for _, host := range hostItems {
-		eg.Go(app.handlePing(ctx, sigterm, host))
+	for _, g := range t.groups {
+		done[g.Name] = make(chan struct{})
 	}
 
-	// Create a channel to signal when all goroutines are done.
-	doneChan := make(chan error)
+	// Iterate over all hosts and ping them, buffering the results so no
+	// goroutine is blocked once the run is done.
+	results := make(chan hostResult, len(t.hosts))
+	for i, host := range t.hosts {
+		wg.Go(func() {
+			results <- hostResult{index: i, err: app.handlePing(runCtx, sigterm, host, t.progress[i], done)()}
+		})
+	}
 
-	go func() {
-		// Wait for all goroutines or for the first error.
-		if err := eg.Wait(); err != nil {
-			doneChan <- err
-		}
+	satisfied := make(map[string]bool, len(t.groups))
+	for {
+		select {
+		case r := <-results:
+			if errors.Is(r.err, ErrInterrupted) {
+				return r.err
+			}
+
+			// Hosts failing once the global timeout is reached are left
+			// pending, so they're reported along with the other hosts that
+			// are still down.
+			if r.err != nil && ctx.Err() != nil {
+				return t.timedOut(timeout)
+			}
+
+			t.finish(r.index, r.err)
 
-		// All goroutines finished successfully.
-		close(doneChan)
-	}()
+			// Unblock the hosts depending on the groups satisfied so far.
+			for _, g := range t.groups {
+				if !satisfied[g.Name] && t.statusOf(g.Name) == statusReached {
+					satisfied[g.Name] = true
+					close(done[g.Name])
+				}
+			}
+
+			if finished, err := t.result(); finished {
+				// A host reaching its own timeout ends the run before the
+				// global one, so the other hosts still down are listed too.
+				if errors.Is(err, ErrTimeout) {
+					if summary := t.pendingSummary(); summary != "" {
+						err = fmt.Errorf("%w, also still waiting for:%s", err, summary)
+					}
+				}
 
-	select {
-	case err := <-doneChan:
-		// Immediately return the first error encountered.
-		return err
-	case <-ctx.Done():
-		// Global timeout triggered.
-		return fmt.Errorf("%s timeout reached before all hosts were up", app.Timeout)
+				return err
+			}
+		case <-sigterm.Done():
+			return ErrInterrupted
+		case <-ctx.Done():
+			return t.timedOut(timeout)
+		}
 	}
 }
 
 // handlePing pings the host asynchronously and returns an error if the host
-// is not reachable.
-func (app *App) handlePing(ctx, sigterm context.Context, h matchedURLItem) func() error {
+// does not reach the expected state: reachable by default, or unreachable
+// when the host is awaited to go down. Hosts failing with a permanent error,
+// such as rejected credentials, are not retried unless App.RetryAllErrors is
+// set, since waiting longer won't fix them. Every attempt is recorded in p.
+func (app *App) handlePing(ctx, sigterm context.Context, h matchedURLItem, p *progress, done map[string]chan struct{}) func() error {
 	return func() error {
 		startTime := time.Now()
+		attempt := 0
+		var s streak
 
-		// Ping right away the first time
-		if err := h.Pinger.Ping(ctx); err == nil {
-			app.printOnVerbose("> up:   %s (after %s)", app.pad(h.Raw), time.Since(startTime))
-			return nil // Host is reachable, break the loop.
-		} else {
-			app.printOnVerbose("> down: %s -- %s", app.pad(h.Raw), err.Error())
+		// Each host can have its own timeout, shorter than the global one,
+		// which also covers the time waiting for its dependencies.
+		ctx, cancel := context.WithTimeout(ctx, h.Timeout)
+		defer cancel()
+
+		if err := app.waitForDependencies(ctx, sigterm, h, done, startTime); err != nil {
+			return err
 		}
 
-		// Create a ticker to ping the host every `app.Every` duration.
-		ticker := time.NewTicker(app.Every)
-		defer ticker.Stop()
+		// Schedule the attempts based on the retry strategy, with `h.Every`
+		// as the base interval.
+		sched := newScheduler(h.Retry, h.Every, h.MaxEvery)
 
 		for {
+			// Ping the host and check if it reached the expected state.
+			attempt++
+			attemptStart := time.Now()
+			reached, err := app.ping(ctx, h, startTime, attempt, &s)
+
+			// An attempt cut short by a signal says nothing about the host,
+			// which matters for hosts awaited to go down.
+			if sigterm.Err() != nil {
+				return ErrInterrupted
+			}
+
+			p.record(attemptStart, (err == nil) != h.Down, h.redactError(err))
+			if reached {
+				// Host reached the expected state, unblock the hosts depending
+				// on it and break the loop.
+				if ch, ok := done[h.Name]; ok {
+					close(ch)
+				}
+
+				return nil
+			}
+
+			if !h.Down && !app.RetryAllErrors && probes.IsPermanent(err) {
+				return fmt.Errorf("unable to wait for %q: %w", h.String(), h.redactError(err))
+			}
+
+			// The next attempt is only scheduled if the run can go on, since
+			// after a slow attempt it's due right away, and select would
+			// otherwise pick it at random over the timeout or the signal.
+			var retry <-chan time.Time
+			if sigterm.Err() == nil && ctx.Err() == nil {
+				retry = time.After(time.Until(attemptStart.Add(sched.next())))
+			}
+
 			select {
 			case <-sigterm.Done():
 				// User requested early termination.
-				return fmt.Errorf("user requested early termination")
+				return ErrInterrupted
 			case <-ctx.Done():
+				// User requested early termination, which also cancels ctx.
+				if sigterm.Err() != nil {
+					return ErrInterrupted
+				}
+
+				// Another host failed, so this one is no longer needed.
+				if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					return ctx.Err()
+				}
+
 				// Timeout reached.
-				return fmt.Errorf("timeout reached while waiting for %q", h)
-			case <-ticker.C:
-				// Ping the host and check if it's reachable.
-				if err := h.Pinger.Ping(ctx); err == nil {
-					app.printOnVerbose("> up:   %s (after %s)", app.pad(h.Raw), time.Since(startTime))
-					return nil // Host is reachable, break the loop.
-				} else {
-					app.printOnVerbose("> down: %s -- %s", app.pad(h.Raw), err.Error())
+				event := Event{
+					Type:     EventTimeout,
+					Time:     time.Now(),
+					HostInfo: h.info(),
+					Attempt:  attempt,
+					Elapsed:  time.Since(startTime),
 				}
+
+				if err != nil {
+					event.Error = h.redact(err.Error())
+				}
+
+				app.Reporter.Report(event)
+				return fmt.Errorf("%s %w while waiting for %q (%s)", h.Timeout, ErrTimeout, h.String(), p.describe(&h))
+			case <-retry:
+				// The wait time is measured from the start of the attempt, so
+				// slow attempts don't delay the next one further.
 			}
 		}
 	}
 }
 
-// printOnVerbose prints the message to the standard output if the verbose
-// flag is enabled.
-func (app *App) printOnVerbose(format string, args ...interface{}) {
-	if app.Verbose {
-		fmt.Fprintf(os.Stdout, format+"\n", args...)
+// streak tracks the consecutive attempts in which a host was in the
+// expected state.
+type streak struct {
+	count int
+	since time.Time
+}
+
+// ping pings the host once, reports the outcome and returns whether the
+// host reached the expected state, along with the error from the probe, if
+// any. A host awaited to go down is in its expected state when the ping
+// fails, and reaches it once it has been in that state for long enough, as
+// tracked by the streak.
+func (app *App) ping(ctx context.Context, h matchedURLItem, startTime time.Time, attempt int, s *streak) (bool, error) {
+	attemptStart := time.Now()
+	err := h.Pinger.Ping(ctx)
+
+	success := (err == nil) != h.Down
+	if !success {
+		*s = streak{}
+	} else if s.count++; s.count == 1 {
+		s.since = attemptStart
+	}
+
+	event := Event{
+		Type:     EventAttempt,
+		Time:     time.Now(),
+		HostInfo: h.info(),
+		Attempt:  attempt,
+		Latency:  time.Since(attemptStart),
+		Success:  success,
 	}
+
+	// Only report the streak if more than a single attempt is required.
+	if h.SuccessThreshold > 1 || h.StableFor > 0 {
+		event.Streak = s.count
+		event.Threshold = h.SuccessThreshold
+		event.StableFor = h.StableFor
+
+		if success {
+			event.Stable = event.Time.Sub(s.since)
+		}
+	}
+
+	if err != nil {
+		event.Error = h.redact(err.Error())
+	}
+
+	app.Reporter.Report(event)
+
+	reached := success && s.count >= h.SuccessThreshold && event.Time.Sub(s.since) >= h.StableFor
+	if !reached {
+		return false, err
+	}
+
+	event.Type = EventUp
+	if h.Down {
+		event.Type = EventDown
+	}
+
+	event.Elapsed = time.Since(startTime)
+	app.Reporter.Report(event)
+	return true, err
 }
 
-// parseHost parses the host string and returns a URL.
+// parseHost parses the host string and returns a URL. Hosts prefixed with
+// "!" are awaited until they go down. Probe-specific options can be provided
+// in the URL fragment, with the same format as a query string, such as
+// "http://localhost#method=POST&status=204".
 func parseHost(hostStr string) (*matchedURLItem, error) {
+	hostStr, down := strings.CutPrefix(hostStr, downPrefix)
+
+	// Extract the probe options from the fragment, if any
+	hostStr, fragment, _ := strings.Cut(hostStr, "#")
+	params, err := url.ParseQuery(fragment)
+	if err != nil {
+		return nil, fmt.Errorf("invalid options in URL fragment: %w", err)
+	}
+
 	// If no scheme, assume tcp
 	if !strings.Contains(hostStr, "://") {
 		hostStr = "tcp://" + hostStr
@@ -211,19 +654,19 @@ func parseHost(hostStr string) (*matchedURLItem, error) {
 	// Find the Pinger
 	pingerCtor, ok := pingerRegistry[scheme]
 	if !ok {
-		return nil, fmt.Errorf("no handler registered for scheme %q (host: %q)", scheme, hostStr)
+		return nil, fmt.Errorf("no handler registered for scheme %q (host: %q)", scheme, RedactURL(hostStr))
 	}
 
-	// Return the URL and the Pinger
-	return &matchedURLItem{
+	// Return the URL and the Pinger, keeping track of the credentials to
+	// mask them in the output
+	matched := &matchedURLItem{
 		Raw:    hostStr,
+		Scheme: scheme,
 		Pinger: pingerCtor(),
-	}, nil
-}
+		Params: params,
+		Down:   down,
+	}
 
-// pad pads the string to the configured padding based on the longest host
-// full string URL representation (including protocol).
-func (app *App) pad(str string) string {
-	format := fmt.Sprintf("%%-%ds", app.padding)
-	return fmt.Sprintf(format, str)
+	matched.addSecrets(secretsFromURL(hostStr)...)
+	return matched, nil
 }
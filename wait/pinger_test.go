@@ -1,11 +1,59 @@
 package wait
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io"
+	"net/url"
 	"os"
+	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/patrickdappollonio/wait-for/wait/probes"
 )
 
+// fakePinger is a pinger that returns the configured error on every ping.
+type fakePinger struct {
+	err error
+}
+
+func (f *fakePinger) Bootstrap(string, probes.Options) error { return nil }
+func (f *fakePinger) Ping(context.Context) error             { return f.err }
+
+// sequencePinger is a pinger that returns the configured errors in order,
+// repeating the last one once all of them were returned.
+type sequencePinger struct {
+	errs []error
+	i    int
+}
+
+func (s *sequencePinger) Bootstrap(string, probes.Options) error { return nil }
+func (s *sequencePinger) Ping(context.Context) error {
+	err := s.errs[min(s.i, len(s.errs)-1)]
+	s.i++
+	return err
+}
+
+// blockingPinger is a pinger whose attempts only end once their context is
+// canceled, signaling started when the first one begins and counting them.
+type blockingPinger struct {
+	started  chan struct{}
+	once     sync.Once
+	attempts atomic.Int32
+}
+
+func (b *blockingPinger) Bootstrap(string, probes.Options) error { return nil }
+func (b *blockingPinger) Ping(ctx context.Context) error {
+	b.attempts.Add(1)
+	b.once.Do(func() { close(b.started) })
+	<-ctx.Done()
+	return ctx.Err()
+}
+
 func TestParseHost(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -31,6 +79,31 @@ func TestParseHost(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:    "Down prefix",
+			hostStr: "!http://example.com",
+			want: &matchedURLItem{
+				Raw:    "http://example.com",
+				Pinger: pingerRegistry["http"](),
+				Down:   true,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Options in fragment",
+			hostStr: "http://example.com/healthz#method=POST&status=204",
+			want: &matchedURLItem{
+				Raw:    "http://example.com/healthz",
+				Pinger: pingerRegistry["http"](),
+				Params: url.Values{"method": {"POST"}, "status": {"204"}},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Invalid options in fragment",
+			hostStr: "http://example.com#status=%zz",
+			wantErr: true,
+		},
 		{
 			name:    "Invalid URL without scheme",
 			hostStr: "://example.com",
@@ -60,6 +133,14 @@ func TestParseHost(t *testing.T) {
 				t.Errorf("parseHost() got = %v, want %v", got.Raw, tt.want.Raw)
 			}
 
+			if !tt.wantErr && len(tt.want.Params) > 0 && !reflect.DeepEqual(got.Params, tt.want.Params) {
+				t.Errorf("parseHost() got Params = %v, want %v", got.Params, tt.want.Params)
+			}
+
+			if !tt.wantErr && got.Down != tt.want.Down {
+				t.Errorf("parseHost() got Down = %v, want %v", got.Down, tt.want.Down)
+			}
+
 			if !tt.wantErr && got.Pinger == nil {
 				t.Errorf("parseHost() got Pinger = nil, want non-nil")
 			}
@@ -67,81 +148,344 @@ func TestParseHost(t *testing.T) {
 	}
 }
 
-func TestStringifyHosts(t *testing.T) {
+func TestAppPing(t *testing.T) {
 	tests := []struct {
 		name string
-		urls []matchedURLItem
-		want string
+		err  error
+		down bool
+		want bool
 	}{
 		{
-			name: "Single URL",
-			urls: []matchedURLItem{
-				{
-					Raw: "http://example.com",
-				},
-			},
-			want: `"http://example.com"`,
+			name: "Up and awaited up",
+			want: true,
 		},
 		{
-			name: "Multiple URLs",
-			urls: []matchedURLItem{
-				{
-					Raw: "http://example.com",
-				},
-				{
-					Raw: "https://example.org",
-				},
-			},
-			want: `"http://example.com", "https://example.org"`,
+			name: "Down and awaited up",
+			err:  errors.New("connection refused"),
+			want: false,
+		},
+		{
+			name: "Up and awaited down",
+			down: true,
+			want: false,
+		},
+		{
+			name: "Down and awaited down",
+			err:  errors.New("connection refused"),
+			down: true,
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &App{Reporter: NewTextReporter(io.Discard, false)}
+			h := matchedURLItem{Raw: "tcp://example.com", Pinger: &fakePinger{err: tt.err}, Down: tt.down}
+			if got, _ := app.ping(context.Background(), h, time.Now(), 1, &streak{}); got != tt.want {
+				t.Errorf("ping() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppRunUntilDown(t *testing.T) {
+	pingerRegistry["fake"] = func() Pinger { return &fakePinger{err: errors.New("connection refused")} }
+	defer delete(pingerRegistry, "fake")
+
+	app := &App{Hosts: []string{"fake://example.com"}, Timeout: time.Second, Every: 10 * time.Millisecond, Reporter: NewTextReporter(io.Discard, false)}
+	if err := app.Run(); err == nil {
+		t.Errorf("Run() error = nil, want timeout")
+	}
+
+	app = &App{Hosts: []string{"fake://example.com"}, Timeout: time.Second, Every: 10 * time.Millisecond, Until: UntilDown, Reporter: NewTextReporter(io.Discard, false)}
+	if err := app.Run(); err != nil {
+		t.Errorf("Run() error = %v, want nil", err)
+	}
+
+	app = &App{Hosts: []string{"fake://example.com"}, Until: "sideways"}
+	if err := app.Run(); err == nil {
+		t.Errorf("Run() error = nil, want invalid until")
+	}
+}
+
+func TestAppRunTargets(t *testing.T) {
+	pingerRegistry["fake"] = func() Pinger { return &fakePinger{err: errors.New("connection refused")} }
+	defer delete(pingerRegistry, "fake")
+
+	// The per-host timeout is shorter than the global one, so the run should
+	// finish well before the global timeout.
+	app := &App{
+		Targets: []Target{{URL: "fake://example.com", Name: "fake", Timeout: 50 * time.Millisecond}},
+		Timeout: 10 * time.Second,
+		Every:   10 * time.Millisecond,
+
+		Reporter: NewTextReporter(io.Discard, false),
+	}
+
+	start := time.Now()
+	err := app.Run()
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("Run() error = %v, want %v", err, ErrTimeout)
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Run() took %s, want it to use the per-host timeout", elapsed)
+	}
+
+	app = &App{
+		Targets: []Target{{URL: "fake://a", Name: "same"}, {URL: "fake://b", Name: "same"}},
+		Timeout: time.Second,
+		Every:   10 * time.Millisecond,
+
+		Reporter: NewTextReporter(io.Discard, false),
+	}
+	if err := app.Run(); err == nil {
+		t.Errorf("Run() error = nil, want duplicated name error")
+	}
+}
+
+func TestAppRunErrors(t *testing.T) {
+	pingerRegistry["refused"] = func() Pinger { return &fakePinger{err: errors.New("connection refused")} }
+	pingerRegistry["denied"] = func() Pinger { return &fakePinger{err: fmt.Errorf("%w: access denied", probes.ErrAuthentication)} }
+	defer delete(pingerRegistry, "refused")
+	defer delete(pingerRegistry, "denied")
+
+	var cfgErr *ConfigError
+
+	app := &App{Hosts: []string{"unknown://example.com"}, Timeout: time.Second, Every: 10 * time.Millisecond, Reporter: NewTextReporter(io.Discard, false)}
+	if err := app.Run(); !errors.As(err, &cfgErr) {
+		t.Errorf("Run() error = %v, want a *ConfigError", err)
+	}
+
+	app = &App{Targets: []Target{{URL: "refused://example.com", SuccessThreshold: -1}}, Timeout: time.Second, Every: 10 * time.Millisecond, Reporter: NewTextReporter(io.Discard, false)}
+	if err := app.Run(); !errors.As(err, &cfgErr) {
+		t.Errorf("Run() error = %v, want a *ConfigError", err)
+	}
+
+	for _, every := range []time.Duration{0, -time.Second} {
+		app = &App{Hosts: []string{"refused://example.com"}, Timeout: time.Second, Every: every, Reporter: NewTextReporter(io.Discard, false)}
+		if err := app.Run(); !errors.As(err, &cfgErr) {
+			t.Errorf("Run() with every %s error = %v, want a *ConfigError", every, err)
+		}
+	}
+
+	app = &App{Targets: []Target{{URL: "refused://example.com", Every: -time.Second}}, Timeout: time.Second, Every: 10 * time.Millisecond, Reporter: NewTextReporter(io.Discard, false)}
+	if err := app.Run(); !errors.As(err, &cfgErr) {
+		t.Errorf("Run() error = %v, want a *ConfigError for a negative per-host every", err)
+	}
+
+	app = &App{Hosts: []string{"refused://example.com"}, AttemptTimeout: -time.Second, Timeout: time.Second, Every: 10 * time.Millisecond, Reporter: NewTextReporter(io.Discard, false)}
+	if err := app.Run(); !errors.As(err, &cfgErr) {
+		t.Errorf("Run() error = %v, want a *ConfigError for a negative attempt timeout", err)
+	}
+
+	app = &App{Targets: []Target{{URL: "refused://example.com", AttemptTimeout: -time.Second}}, Timeout: time.Second, Every: 10 * time.Millisecond, Reporter: NewTextReporter(io.Discard, false)}
+	if err := app.Run(); !errors.As(err, &cfgErr) {
+		t.Errorf("Run() error = %v, want a *ConfigError for a negative per-host attempt timeout", err)
+	}
+
+	app = &App{Hosts: []string{"refused://example.com"}, Retry: RetryExponential, MaxEvery: 5 * time.Millisecond, Timeout: time.Second, Every: 10 * time.Millisecond, Reporter: NewTextReporter(io.Discard, false)}
+	if err := app.Run(); !errors.As(err, &cfgErr) {
+		t.Errorf("Run() error = %v, want a *ConfigError for a max every shorter than every", err)
+	}
+
+	// The default maximum is never shorter than every, since it wasn't set
+	// explicitly.
+	app = &App{Hosts: []string{"refused://example.com"}, Retry: RetryExponential, Timeout: 50 * time.Millisecond, Every: DefaultMaxEvery + time.Second, Reporter: NewTextReporter(io.Discard, false)}
+	if err := app.Run(); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Run() error = %v, want ErrTimeout with every longer than the default max every", err)
+	}
+
+	app = &App{Hosts: []string{"refused://example.com"}, Retry: "random", Timeout: time.Second, Every: 10 * time.Millisecond, Reporter: NewTextReporter(io.Discard, false)}
+	if err := app.Run(); !errors.As(err, &cfgErr) {
+		t.Errorf("Run() error = %v, want a *ConfigError", err)
+	}
+
+	app = &App{Hosts: []string{"refused://example.com"}, Timeout: 50 * time.Millisecond, Every: 10 * time.Millisecond, Reporter: NewTextReporter(io.Discard, false)}
+	if err := app.Run(); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Run() error = %v, want ErrTimeout", err)
+	}
+
+	app = &App{Hosts: []string{"denied://example.com"}, Timeout: 10 * time.Second, Every: 10 * time.Millisecond, Reporter: NewTextReporter(io.Discard, false)}
+	if err := app.Run(); !errors.Is(err, probes.ErrAuthentication) {
+		t.Errorf("Run() error = %v, want ErrAuthentication", err)
+	}
+
+	pingerRegistry["missing"] = func() Pinger { return &fakePinger{err: probes.Permanent(errors.New("no such host"))} }
+	defer delete(pingerRegistry, "missing")
+
+	app = &App{Hosts: []string{"missing://example.com"}, Timeout: 10 * time.Second, Every: 10 * time.Millisecond, Reporter: NewTextReporter(io.Discard, false)}
+	if err := app.Run(); !errors.Is(err, probes.ErrPermanent) {
+		t.Errorf("Run() error = %v, want ErrPermanent", err)
+	}
+
+	app = &App{Hosts: []string{"missing://example.com"}, RetryAllErrors: true, Timeout: 50 * time.Millisecond, Every: 10 * time.Millisecond, Reporter: NewTextReporter(io.Discard, false)}
+	if err := app.Run(); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Run() error = %v, want ErrTimeout when retrying all errors", err)
+	}
+}
+
+func TestAppPingStreak(t *testing.T) {
+	refused := errors.New("connection refused")
+
+	tests := []struct {
+		name      string
+		errs      []error
+		down      bool
+		threshold int
+		stableFor time.Duration
+		want      int // zero to only check the stable duration
+	}{
+		{
+			name:      "Single attempt",
+			errs:      []error{refused, nil},
+			threshold: 1,
+			want:      2,
 		},
 		{
-			name: "No URLs",
-			urls: []matchedURLItem{},
-			want: "",
+			name:      "Consecutive successes",
+			errs:      []error{nil, nil, refused, nil, nil, nil},
+			threshold: 3,
+			want:      6,
+		},
+		{
+			name:      "Consecutive failures when awaited down",
+			errs:      []error{refused, nil, refused, refused},
+			down:      true,
+			threshold: 2,
+			want:      4,
+		},
+		{
+			name:      "Stable duration",
+			errs:      []error{nil},
+			threshold: 1,
+			stableFor: 30 * time.Millisecond,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := stringifyHosts(tt.urls); got != tt.want {
-				t.Errorf("stringifyHosts() = %v, want %v", got, tt.want)
+			app := &App{Reporter: NewTextReporter(io.Discard, false)}
+			h := matchedURLItem{
+				Raw:              "tcp://example.com",
+				Pinger:           &sequencePinger{errs: tt.errs},
+				Down:             tt.down,
+				SuccessThreshold: tt.threshold,
+				StableFor:        tt.stableFor,
 			}
+
+			var s streak
+			start := time.Now()
+			for attempt := 1; attempt <= 10; attempt++ {
+				reached, _ := app.ping(context.Background(), h, time.Now(), attempt, &s)
+				if reached {
+					if tt.want == 0 && time.Since(start) < tt.stableFor {
+						t.Errorf("ping() reached the expected state after %s, want at least %s", time.Since(start), tt.stableFor)
+					}
+
+					if tt.want != 0 && attempt != tt.want {
+						t.Errorf("ping() reached the expected state on attempt %d, want %d", attempt, tt.want)
+					}
+					return
+				}
+
+				time.Sleep(12 * time.Millisecond)
+			}
+
+			t.Errorf("ping() never reached the expected state, want it on attempt %d", tt.want)
 		})
 	}
 }
 
-func TestAppPad(t *testing.T) {
-	a := &App{padding: 5}
-	if got := a.pad("a"); got != "a    " {
-		t.Errorf("pad() = %q", got)
+func TestAppRunInterrupted(t *testing.T) {
+	for _, until := range []string{UntilUp, UntilDown} {
+		t.Run(until, func(t *testing.T) {
+			pinger := &blockingPinger{started: make(chan struct{})}
+			pingerRegistry["blocking"] = func() Pinger { return pinger }
+			defer delete(pingerRegistry, "blocking")
+
+			app := &App{
+				Hosts:          []string{"blocking://example.com"},
+				Until:          until,
+				Timeout:        10 * time.Second,
+				Every:          10 * time.Millisecond,
+				AttemptTimeout: 10 * time.Second,
+				Reporter:       NewTextReporter(io.Discard, false),
+			}
+
+			errc := make(chan error, 1)
+			go func() { errc <- app.Run() }()
+
+			// Send the signal while the first attempt is still in flight.
+			<-pinger.started
+			proc, err := os.FindProcess(os.Getpid())
+			if err != nil {
+				t.Fatalf("os.FindProcess() error = %v", err)
+			}
+
+			if err := proc.Signal(os.Interrupt); err != nil {
+				t.Skipf("unable to send an interrupt signal: %v", err)
+			}
+
+			select {
+			case err := <-errc:
+				if !errors.Is(err, ErrInterrupted) {
+					t.Errorf("Run() error = %v, want ErrInterrupted", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("Run() didn't return after the signal")
+			}
+		})
+	}
+}
+
+func TestAppRunSlowAttemptTimeout(t *testing.T) {
+	// An attempt ending with the timeout leaves no time before the next
+	// one, which must not be started anyway.
+	for range 5 {
+		pinger := &blockingPinger{started: make(chan struct{})}
+		pingerRegistry["blocking"] = func() Pinger { return pinger }
+
+		app := &App{
+			Hosts:          []string{"blocking://example.com"},
+			Timeout:        20 * time.Millisecond,
+			Every:          10 * time.Millisecond,
+			AttemptTimeout: 10 * time.Second,
+			Reporter:       NewTextReporter(io.Discard, false),
+		}
+
+		err := app.Run()
+		delete(pingerRegistry, "blocking")
+
+		if !errors.Is(err, ErrTimeout) {
+			t.Fatalf("Run() error = %v, want ErrTimeout", err)
+		}
+
+		if got := pinger.attempts.Load(); got != 1 {
+			t.Fatalf("Run() made %d attempts, want 1", got)
+		}
 	}
 }
 
-func TestPrintOnVerbose(t *testing.T) {
-	a := &App{Verbose: true}
-	r, w, err := os.Pipe()
-	if err != nil {
-		t.Fatalf("pipe error: %v", err)
-	}
-	old := os.Stdout
-	os.Stdout = w
-	a.printOnVerbose("msg")
-	w.Close()
-	os.Stdout = old
-	out, _ := io.ReadAll(r)
-	if string(out) != "msg\n" {
-		t.Errorf("unexpected output: %q", string(out))
-	}
-
-	// now ensure nothing printed when verbose false
-	r, w, _ = os.Pipe()
-	os.Stdout = w
-	a.Verbose = false
-	a.printOnVerbose("nope")
-	w.Close()
-	os.Stdout = old
-	out, _ = io.ReadAll(r)
-	if len(out) != 0 {
-		t.Errorf("expected no output, got %q", string(out))
+func TestAppRunStartTimeout(t *testing.T) {
+	pingerRegistry["up"] = func() Pinger { return &fakePinger{} }
+	defer delete(pingerRegistry, "up")
+
+	// The run waits as long as the longest timeout among all hosts, which
+	// is the one reported when it starts.
+	rec := &recorder{}
+	app := &App{
+		Targets:  []Target{{URL: "up://example.com", Timeout: 2 * time.Minute}},
+		Timeout:  10 * time.Second,
+		Every:    10 * time.Millisecond,
+		Reporter: rec,
+	}
+
+	if err := app.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if got := rec.events[0]; got.Type != EventStart || got.Timeout != 2*time.Minute {
+		t.Errorf("Run() first event = %s with timeout %s, want %s with timeout %s", got.Type, got.Timeout, EventStart, 2*time.Minute)
 	}
 }
@@ -0,0 +1,36 @@
+package probes
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// passwordFileParam is the option holding the path to a file with the
+// password, such as a Docker or Kubernetes secret mount.
+const passwordFileParam = "password_file"
+
+// passwordFromOptions returns the password from the URL userinfo or, if
+// the password_file option is set, the contents of that file without
+// trailing newlines. Providing both is an error, since it's unclear which
+// one should be used.
+func passwordFromOptions(u *url.URL, opts Options) (string, error) {
+	pass, hasPass := u.User.Password()
+
+	path := opts.Params.Get(passwordFileParam)
+	if path == "" {
+		return pass, nil
+	}
+
+	if hasPass {
+		return "", fmt.Errorf("the password can't be provided both in the URL and in %s", passwordFileParam)
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("unable to read %s: %w", passwordFileParam, err)
+	}
+
+	return strings.TrimRight(string(contents), "\r\n"), nil
+}
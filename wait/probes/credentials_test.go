@@ -0,0 +1,91 @@
+package probes
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPasswordFromOptions(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(secret, []byte("p@ss:w/rd\n"), 0o600); err != nil {
+		t.Fatalf("unable to write password: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		host    string
+		params  url.Values
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Password in URL",
+			host: "mysql://root:secret@db:3306",
+			want: "secret",
+		},
+		{
+			name: "No password",
+			host: "mysql://root@db:3306",
+			want: "",
+		},
+		{
+			name:   "Password from file",
+			host:   "postgres://app@db:5432/app",
+			params: url.Values{"password_file": {secret}},
+			want:   "p@ss:w/rd",
+		},
+		{
+			name:    "Password in both places",
+			host:    "postgres://app:secret@db:5432/app",
+			params:  url.Values{"password_file": {secret}},
+			wantErr: true,
+		},
+		{
+			name:    "Missing file",
+			host:    "postgres://app@db:5432/app",
+			params:  url.Values{"password_file": {filepath.Join(t.TempDir(), "missing")}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.host)
+			if err != nil {
+				t.Fatalf("url.Parse() error = %v", err)
+			}
+
+			got, err := passwordFromOptions(u, Options{Params: tt.params})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("passwordFromOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("passwordFromOptions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPostgresBootstrapPasswordFile(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(secret, []byte("p@ss:w/rd\n"), 0o600); err != nil {
+		t.Fatalf("unable to write password: %v", err)
+	}
+
+	p := &PostgresPinger{}
+	if err := p.Bootstrap("postgres://app@db:5432/app?sslmode=disable", Options{Params: url.Values{"password_file": {secret}}}); err != nil {
+		t.Fatalf("Bootstrap() error = %v", err)
+	}
+
+	u, err := url.Parse(p.DSN)
+	if err != nil {
+		t.Fatalf("url.Parse(DSN) error = %v", err)
+	}
+
+	if pass, _ := u.User.Password(); pass != "p@ss:w/rd" {
+		t.Errorf("DSN password = %q, want %q", pass, "p@ss:w/rd")
+	}
+}
@@ -0,0 +1,347 @@
+package probes
+
+import (
+	"cmp"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// dnsTypes are the record types the dns probe can query, by name.
+var dnsTypes = map[string]dnsmessage.Type{
+	"A":     dnsmessage.TypeA,
+	"AAAA":  dnsmessage.TypeAAAA,
+	"CNAME": dnsmessage.TypeCNAME,
+	"MX":    dnsmessage.TypeMX,
+	"NS":    dnsmessage.TypeNS,
+	"PTR":   dnsmessage.TypePTR,
+	"SRV":   dnsmessage.TypeSRV,
+	"TXT":   dnsmessage.TypeTXT,
+}
+
+// dnsMaxUDPSize is the size of the buffer for responses over UDP. Servers
+// truncate larger responses, which are then retried over TCP.
+const dnsMaxUDPSize = 4096
+
+// DNSPinger is a pinger for DNS records. It sends a query to the given
+// server, instead of the system resolver, and only considers the record up
+// once the server answers with it.
+type DNSPinger struct {
+	Server  string
+	Name    string
+	Type    dnsmessage.Type
+	TCP     bool
+	Timeout time.Duration
+
+	// Expect, if set, must match one of the records in the answer.
+	Expect string
+
+	// MinRecords is the minimum number of records in the answer.
+	MinRecords int
+}
+
+// Bootstrap sets up the pinger with the DNS URL.
+// Expected URL format: dns://server:port/name?type=A
+func (d *DNSPinger) Bootstrap(host string, opts Options) error {
+	if err := opts.checkParams("expect", "min_records", "tcp"); err != nil {
+		return err
+	}
+
+	u, err := url.Parse(host)
+	if err != nil {
+		return fmt.Errorf("failed to parse host %q: %v", host, err)
+	}
+
+	if u.Scheme != "dns" {
+		return fmt.Errorf("invalid scheme for dns probe: %s", u.Scheme)
+	}
+
+	if u.Hostname() == "" {
+		return fmt.Errorf("no server specified for %s scheme", u.Scheme)
+	}
+
+	d.Server = net.JoinHostPort(u.Hostname(), cmp.Or(u.Port(), "53"))
+
+	d.Name = strings.Trim(u.Path, "/")
+	if d.Name == "" {
+		return fmt.Errorf("no name to resolve specified for %s scheme: expected dns://server:port/name", u.Scheme)
+	}
+
+	if _, err := dnsmessage.NewName(dnsFQDN(d.Name)); err != nil {
+		return fmt.Errorf("invalid name to resolve %q: %w", d.Name, err)
+	}
+
+	query := u.Query()
+	for k := range query {
+		if k != "type" {
+			return fmt.Errorf("unknown query parameter %q for dns probe: only \"type\" is supported", k)
+		}
+	}
+
+	d.Type = dnsmessage.TypeA
+	if v := query.Get("type"); v != "" {
+		t, ok := dnsTypes[strings.ToUpper(v)]
+		if !ok {
+			names := make([]string, 0, len(dnsTypes))
+			for name := range dnsTypes {
+				names = append(names, name)
+			}
+			slices.Sort(names)
+
+			return fmt.Errorf("unsupported record type %q: must be one of %s", v, strings.Join(names, ", "))
+		}
+		d.Type = t
+	}
+
+	d.TCP = false
+	if v := opts.Params.Get("tcp"); v != "" {
+		d.TCP, err = strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid value for tcp: %q", v)
+		}
+	}
+
+	d.MinRecords = 1
+	if v := opts.Params.Get("min_records"); v != "" {
+		d.MinRecords, err = strconv.Atoi(v)
+		if err != nil || d.MinRecords <= 0 {
+			return fmt.Errorf("invalid min_records %q: must be a positive number", v)
+		}
+	}
+
+	d.Expect = ""
+	if v := opts.Params.Get("expect"); v != "" {
+		d.Expect, err = normalizeDNSValue(d.Type, v)
+		if err != nil {
+			return fmt.Errorf("invalid value for expect: %w", err)
+		}
+	}
+
+	d.Timeout = opts.attemptTimeout()
+	return nil
+}
+
+// Ping queries the server for the record and checks the answer.
+func (d *DNSPinger) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, cmp.Or(d.Timeout, DefaultAttemptTimeout))
+	defer cancel()
+
+	name, err := dnsmessage.NewName(dnsFQDN(d.Name))
+	if err != nil {
+		return Permanent(fmt.Errorf("invalid name to resolve %q: %w", d.Name, err))
+	}
+
+	query := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: uint16(rand.Uint32()), RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: name, Type: d.Type, Class: dnsmessage.ClassINET}},
+	}
+
+	var resp *dnsmessage.Message
+	if !d.TCP {
+		resp, err = d.exchange(ctx, "udp", query)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Answers too large for UDP are truncated, and must be queried again
+	// over TCP to get every record.
+	if d.TCP || resp.Truncated {
+		resp, err = d.exchange(ctx, "tcp", query)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch resp.RCode {
+	case dnsmessage.RCodeSuccess:
+	case dnsmessage.RCodeNameError:
+		return fmt.Errorf("name %q doesn't exist", d.Name)
+	default:
+		return fmt.Errorf("dns server replied to the query for %q with %s", d.Name, strings.TrimPrefix(resp.RCode.String(), "RCode"))
+	}
+
+	var values []string
+	for _, rr := range resp.Answers {
+		if rr.Header.Type != d.Type {
+			continue
+		}
+
+		if v, ok := dnsRecordValue(rr.Body); ok {
+			values = append(values, v)
+		}
+	}
+
+	typ := strings.TrimPrefix(d.Type.String(), "Type")
+	if len(values) == 0 {
+		return fmt.Errorf("no %s records found for %q", typ, d.Name)
+	}
+
+	if len(values) < d.MinRecords {
+		return fmt.Errorf("found %d %s records for %q, want at least %d", len(values), typ, d.Name, d.MinRecords)
+	}
+
+	if d.Expect != "" && !slices.Contains(values, d.Expect) {
+		return fmt.Errorf("none of the %s records for %q match %q, got: %s", typ, d.Name, d.Expect, strings.Join(values, ", "))
+	}
+
+	return nil
+}
+
+// exchange sends the query over the given network and returns the
+// response with the same ID, ignoring any other.
+func (d *DNSPinger) exchange(ctx context.Context, network string, query dnsmessage.Message) (*dnsmessage.Message, error) {
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, Permanent(fmt.Errorf("unable to build the dns query: %w", err))
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, d.Server)
+	if err != nil {
+		return nil, dialError(err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	// Messages over TCP are prefixed with their length.
+	if network == "tcp" {
+		packed = append(binary.BigEndian.AppendUint16(nil, uint16(len(packed))), packed...)
+	}
+
+	if _, err := conn.Write(packed); err != nil {
+		return nil, fmt.Errorf("unable to send the dns query to %s: %w", d.Server, err)
+	}
+
+	for {
+		buf, err := readDNSMessage(conn, network)
+		if err != nil {
+			return nil, fmt.Errorf("no dns response from %s over %s: %w", d.Server, network, err)
+		}
+
+		var resp dnsmessage.Message
+		if err := resp.Unpack(buf); err != nil {
+			return nil, fmt.Errorf("invalid dns response from %s: %w", d.Server, err)
+		}
+
+		if resp.ID != query.ID || !resp.Response || !slices.Equal(resp.Questions, query.Questions) {
+			// A late response to a previous query, over UDP, is skipped.
+			if network == "udp" {
+				continue
+			}
+
+			return nil, fmt.Errorf("unexpected dns response from %s", d.Server)
+		}
+
+		return &resp, nil
+	}
+}
+
+// readDNSMessage reads a single message from the connection.
+func readDNSMessage(conn net.Conn, network string) ([]byte, error) {
+	if network == "udp" {
+		buf := make([]byte, dnsMaxUDPSize)
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		return buf[:n], nil
+	}
+
+	var size [2]byte
+	if _, err := io.ReadFull(conn, size[:]); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, binary.BigEndian.Uint16(size[:]))
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+// dnsRecordValue returns the value of a record as compared with the
+// expected value: an address, a name without the trailing dot, the text of
+// a TXT record or the target and port of an SRV record.
+func dnsRecordValue(body dnsmessage.ResourceBody) (string, bool) {
+	switch b := body.(type) {
+	case *dnsmessage.AResource:
+		return netip.AddrFrom4(b.A).String(), true
+	case *dnsmessage.AAAAResource:
+		return netip.AddrFrom16(b.AAAA).String(), true
+	case *dnsmessage.CNAMEResource:
+		return dnsName(b.CNAME), true
+	case *dnsmessage.MXResource:
+		return dnsName(b.MX), true
+	case *dnsmessage.NSResource:
+		return dnsName(b.NS), true
+	case *dnsmessage.PTRResource:
+		return dnsName(b.PTR), true
+	case *dnsmessage.SRVResource:
+		return net.JoinHostPort(dnsName(b.Target), strconv.Itoa(int(b.Port))), true
+	case *dnsmessage.TXTResource:
+		return strings.Join(b.TXT, ""), true
+	default:
+		return "", false
+	}
+}
+
+// normalizeDNSValue returns the expected value of a record in the same
+// form as dnsRecordValue, so they can be compared.
+func normalizeDNSValue(t dnsmessage.Type, v string) (string, error) {
+	switch t {
+	case dnsmessage.TypeA, dnsmessage.TypeAAAA:
+		addr, err := netip.ParseAddr(v)
+		if err != nil {
+			return "", fmt.Errorf("%q is not an IP address", v)
+		}
+
+		if (t == dnsmessage.TypeA) != addr.Unmap().Is4() {
+			return "", fmt.Errorf("%q is not an address for %s records", v, strings.TrimPrefix(t.String(), "Type"))
+		}
+
+		return addr.Unmap().String(), nil
+	case dnsmessage.TypeSRV:
+		target, port, err := net.SplitHostPort(v)
+		if err != nil {
+			return "", fmt.Errorf("%q must be target:port for SRV records", v)
+		}
+
+		return net.JoinHostPort(strings.ToLower(strings.TrimSuffix(target, ".")), port), nil
+	case dnsmessage.TypeTXT:
+		return v, nil
+	default:
+		return strings.ToLower(strings.TrimSuffix(v, ".")), nil
+	}
+}
+
+// dnsName returns a name in lowercase, without the trailing dot.
+func dnsName(n dnsmessage.Name) string {
+	return strings.ToLower(strings.TrimSuffix(n.String(), "."))
+}
+
+// dnsFQDN returns the name with a trailing dot, so it's never looked up
+// relative to a search domain.
+func dnsFQDN(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+
+	return name + "."
+}
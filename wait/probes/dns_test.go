@@ -0,0 +1,398 @@
+package probes
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// fakeResolver is a minimal DNS server answering over UDP and TCP with the
+// records for each name. Names listed in truncated are answered over UDP
+// with the truncated bit set and no records, as with large answers.
+type fakeResolver struct {
+	records   map[string][]dnsmessage.Resource
+	truncated map[string]bool
+	rcode     map[string]dnsmessage.RCode
+}
+
+// start listens on the same port over UDP and TCP and returns the address
+// of the server.
+func (r *fakeResolver) start(t *testing.T) string {
+	t.Helper()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.ListenPacket() error = %v", err)
+	}
+	t.Cleanup(func() { pc.Close() })
+
+	lis, err := net.Listen("tcp", pc.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	t.Cleanup(func() { lis.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			if resp, ok := r.answer(buf[:n], true); ok {
+				pc.WriteTo(resp, addr)
+			}
+		}
+	}()
+
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				var size [2]byte
+				if _, err := io.ReadFull(conn, size[:]); err != nil {
+					return
+				}
+
+				query := make([]byte, binary.BigEndian.Uint16(size[:]))
+				if _, err := io.ReadFull(conn, query); err != nil {
+					return
+				}
+
+				if resp, ok := r.answer(query, false); ok {
+					conn.Write(append(binary.BigEndian.AppendUint16(nil, uint16(len(resp))), resp...))
+				}
+			}()
+		}
+	}()
+
+	return pc.LocalAddr().String()
+}
+
+// answer builds the response to a query.
+func (r *fakeResolver) answer(query []byte, udp bool) ([]byte, bool) {
+	var msg dnsmessage.Message
+	if err := msg.Unpack(query); err != nil || len(msg.Questions) != 1 {
+		return nil, false
+	}
+
+	q := msg.Questions[0]
+	name := strings.ToLower(q.Name.String())
+
+	resp := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: msg.ID, Response: true, RecursionDesired: msg.RecursionDesired, RCode: dnsmessage.RCodeNameError},
+		Questions: msg.Questions,
+	}
+
+	if rcode, ok := r.rcode[name]; ok {
+		resp.RCode = rcode
+	} else if records, ok := r.records[name]; ok {
+		resp.RCode = dnsmessage.RCodeSuccess
+		if udp && r.truncated[name] {
+			resp.Truncated = true
+		} else {
+			for _, rr := range records {
+				if rr.Header.Type == q.Type {
+					resp.Answers = append(resp.Answers, rr)
+				}
+			}
+		}
+	}
+
+	packed, err := resp.Pack()
+	return packed, err == nil
+}
+
+// dnsRecord returns a resource record for the name with the given body.
+func dnsRecord(name string, typ dnsmessage.Type, body dnsmessage.ResourceBody) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName(name), Type: typ, Class: dnsmessage.ClassINET, TTL: 30},
+		Body:   body,
+	}
+}
+
+func TestDNSPinger_Bootstrap(t *testing.T) {
+	tests := []struct {
+		name           string
+		urlStr         string
+		opts           Options
+		wantServer     string
+		wantName       string
+		wantType       dnsmessage.Type
+		wantExpect     string
+		wantMinRecords int
+		wantTCP        bool
+		wantErr        bool
+	}{
+		{
+			name:           "Default port and type",
+			urlStr:         "dns://10.96.0.10/api.default.svc.cluster.local",
+			wantServer:     "10.96.0.10:53",
+			wantName:       "api.default.svc.cluster.local",
+			wantType:       dnsmessage.TypeA,
+			wantMinRecords: 1,
+		},
+		{
+			name:           "SRV records over TCP",
+			urlStr:         "dns://127.0.0.1:8600/_api._tcp.service.consul.?type=srv",
+			opts:           Options{Params: url.Values{"tcp": {"true"}, "min_records": {"3"}}},
+			wantServer:     "127.0.0.1:8600",
+			wantName:       "_api._tcp.service.consul.",
+			wantType:       dnsmessage.TypeSRV,
+			wantMinRecords: 3,
+			wantTCP:        true,
+		},
+		{
+			name:           "Expected IPv6 address",
+			urlStr:         "dns://[::1]:53/api.example.com?type=AAAA",
+			opts:           Options{Params: url.Values{"expect": {"2001:DB8::1"}}},
+			wantServer:     "[::1]:53",
+			wantName:       "api.example.com",
+			wantType:       dnsmessage.TypeAAAA,
+			wantExpect:     "2001:db8::1",
+			wantMinRecords: 1,
+		},
+		{
+			name:           "Expected CNAME",
+			urlStr:         "dns://ns1.example.com/www.example.com?type=CNAME",
+			opts:           Options{Params: url.Values{"expect": {"LB.example.com."}}},
+			wantServer:     "ns1.example.com:53",
+			wantName:       "www.example.com",
+			wantType:       dnsmessage.TypeCNAME,
+			wantExpect:     "lb.example.com",
+			wantMinRecords: 1,
+		},
+		{
+			name:    "Expected value isn't an address",
+			urlStr:  "dns://127.0.0.1/api.example.com",
+			opts:    Options{Params: url.Values{"expect": {"lb.example.com"}}},
+			wantErr: true,
+		},
+		{
+			name:    "Expected IPv6 address for A records",
+			urlStr:  "dns://127.0.0.1/api.example.com?type=A",
+			opts:    Options{Params: url.Values{"expect": {"::1"}}},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid min_records",
+			urlStr:  "dns://127.0.0.1/api.example.com",
+			opts:    Options{Params: url.Values{"min_records": {"0"}}},
+			wantErr: true,
+		},
+		{
+			name:    "Unsupported type",
+			urlStr:  "dns://127.0.0.1/api.example.com?type=SOA",
+			wantErr: true,
+		},
+		{
+			name:    "Unknown query parameter",
+			urlStr:  "dns://127.0.0.1/api.example.com?class=IN",
+			wantErr: true,
+		},
+		{
+			name:    "No name specified",
+			urlStr:  "dns://127.0.0.1:53",
+			wantErr: true,
+		},
+		{
+			name:    "No server specified",
+			urlStr:  "dns:///api.example.com",
+			wantErr: true,
+		},
+		{
+			name:    "Unknown option",
+			urlStr:  "dns://127.0.0.1/api.example.com",
+			opts:    Options{Params: url.Values{"retries": {"3"}}},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid scheme",
+			urlStr:  "udp://127.0.0.1:53",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pinger := &DNSPinger{}
+			err := pinger.Bootstrap(tt.urlStr, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DNSPinger.Bootstrap() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if pinger.Server != tt.wantServer {
+				t.Errorf("DNSPinger.Server = %q, want %q", pinger.Server, tt.wantServer)
+			}
+
+			if pinger.Name != tt.wantName {
+				t.Errorf("DNSPinger.Name = %q, want %q", pinger.Name, tt.wantName)
+			}
+
+			if pinger.Type != tt.wantType {
+				t.Errorf("DNSPinger.Type = %v, want %v", pinger.Type, tt.wantType)
+			}
+
+			if pinger.Expect != tt.wantExpect {
+				t.Errorf("DNSPinger.Expect = %q, want %q", pinger.Expect, tt.wantExpect)
+			}
+
+			if pinger.MinRecords != tt.wantMinRecords {
+				t.Errorf("DNSPinger.MinRecords = %d, want %d", pinger.MinRecords, tt.wantMinRecords)
+			}
+
+			if pinger.TCP != tt.wantTCP {
+				t.Errorf("DNSPinger.TCP = %v, want %v", pinger.TCP, tt.wantTCP)
+			}
+		})
+	}
+}
+
+func TestDNSPinger_Ping(t *testing.T) {
+	srvName := "_api._tcp.service.consul."
+	server := (&fakeResolver{
+		records: map[string][]dnsmessage.Resource{
+			"api.example.com.": {
+				dnsRecord("api.example.com.", dnsmessage.TypeA, &dnsmessage.AResource{A: [4]byte{10, 0, 0, 1}}),
+				dnsRecord("api.example.com.", dnsmessage.TypeA, &dnsmessage.AResource{A: [4]byte{10, 0, 0, 2}}),
+				dnsRecord("api.example.com.", dnsmessage.TypeAAAA, &dnsmessage.AAAAResource{AAAA: [16]byte{0x20, 0x01, 0x0d, 0xb8, 15: 1}}),
+			},
+			"www.example.com.": {
+				dnsRecord("www.example.com.", dnsmessage.TypeCNAME, &dnsmessage.CNAMEResource{CNAME: dnsmessage.MustNewName("LB.example.com.")}),
+			},
+			"config.example.com.": {
+				dnsRecord("config.example.com.", dnsmessage.TypeTXT, &dnsmessage.TXTResource{TXT: []string{"v=1; ", "ready"}}),
+			},
+			srvName: {
+				dnsRecord(srvName, dnsmessage.TypeSRV, &dnsmessage.SRVResource{Target: dnsmessage.MustNewName("node-1.node.consul."), Port: 8080}),
+				dnsRecord(srvName, dnsmessage.TypeSRV, &dnsmessage.SRVResource{Target: dnsmessage.MustNewName("node-2.node.consul."), Port: 8080}),
+			},
+			"big.example.com.": {
+				dnsRecord("big.example.com.", dnsmessage.TypeA, &dnsmessage.AResource{A: [4]byte{10, 0, 1, 1}}),
+			},
+		},
+		truncated: map[string]bool{"big.example.com.": true},
+		rcode:     map[string]dnsmessage.RCode{"broken.example.com.": dnsmessage.RCodeServerFailure},
+	}).start(t)
+
+	tests := []struct {
+		name    string
+		pinger  *DNSPinger
+		wantErr string
+	}{
+		{
+			name:   "A records",
+			pinger: &DNSPinger{Server: server, Name: "api.example.com", Type: dnsmessage.TypeA},
+		},
+		{
+			name:   "A records over TCP",
+			pinger: &DNSPinger{Server: server, Name: "api.example.com", Type: dnsmessage.TypeA, TCP: true},
+		},
+		{
+			name:   "Expected address",
+			pinger: &DNSPinger{Server: server, Name: "api.example.com", Type: dnsmessage.TypeA, Expect: "10.0.0.2"},
+		},
+		{
+			name:    "Unexpected address",
+			pinger:  &DNSPinger{Server: server, Name: "api.example.com", Type: dnsmessage.TypeA, Expect: "10.0.0.3"},
+			wantErr: `none of the A records for "api.example.com" match "10.0.0.3", got: 10.0.0.1, 10.0.0.2`,
+		},
+		{
+			name:   "Minimum records",
+			pinger: &DNSPinger{Server: server, Name: "api.example.com", Type: dnsmessage.TypeA, MinRecords: 2},
+		},
+		{
+			name:    "Not enough records",
+			pinger:  &DNSPinger{Server: server, Name: "api.example.com", Type: dnsmessage.TypeA, MinRecords: 3},
+			wantErr: `found 2 A records for "api.example.com", want at least 3`,
+		},
+		{
+			name:   "AAAA record",
+			pinger: &DNSPinger{Server: server, Name: "api.example.com", Type: dnsmessage.TypeAAAA, Expect: "2001:db8::1"},
+		},
+		{
+			name:   "CNAME record",
+			pinger: &DNSPinger{Server: server, Name: "www.example.com", Type: dnsmessage.TypeCNAME, Expect: "lb.example.com"},
+		},
+		{
+			name:   "TXT record",
+			pinger: &DNSPinger{Server: server, Name: "config.example.com", Type: dnsmessage.TypeTXT, Expect: "v=1; ready"},
+		},
+		{
+			name:   "SRV records",
+			pinger: &DNSPinger{Server: server, Name: srvName, Type: dnsmessage.TypeSRV, Expect: "node-2.node.consul:8080", MinRecords: 2},
+		},
+		{
+			name:   "Truncated answer retried over TCP",
+			pinger: &DNSPinger{Server: server, Name: "big.example.com", Type: dnsmessage.TypeA, Expect: "10.0.1.1"},
+		},
+		{
+			name:    "No records of the type",
+			pinger:  &DNSPinger{Server: server, Name: "www.example.com", Type: dnsmessage.TypeA},
+			wantErr: `no A records found for "www.example.com"`,
+		},
+		{
+			name:    "Name doesn't exist",
+			pinger:  &DNSPinger{Server: server, Name: "new.example.com", Type: dnsmessage.TypeA},
+			wantErr: `name "new.example.com" doesn't exist`,
+		},
+		{
+			name:    "Server failure",
+			pinger:  &DNSPinger{Server: server, Name: "broken.example.com", Type: dnsmessage.TypeA},
+			wantErr: "ServerFailure",
+		},
+		{
+			name:    "Server not listening over TCP",
+			pinger:  &DNSPinger{Server: "127.0.0.1:1", Name: "api.example.com", Type: dnsmessage.TypeA, TCP: true},
+			wantErr: "connection refused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			err := tt.pinger.Ping(ctx)
+			if (err != nil) != (tt.wantErr != "") {
+				t.Fatalf("DNSPinger.Ping() error = %v, wantErr %q", err, tt.wantErr)
+			}
+
+			if err != nil && !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("DNSPinger.Ping() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDNSPinger_PingTimeout(t *testing.T) {
+	// A UDP socket that never replies, so the query times out.
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.ListenPacket() error = %v", err)
+	}
+	defer pc.Close()
+
+	pinger := &DNSPinger{Server: pc.LocalAddr().String(), Name: "api.example.com", Type: dnsmessage.TypeA, Timeout: 100 * time.Millisecond}
+	if err := pinger.Ping(context.Background()); err == nil || !strings.Contains(err.Error(), "no dns response") {
+		t.Errorf("DNSPinger.Ping() error = %v, want a timeout", err)
+	}
+}
@@ -0,0 +1,79 @@
+package probes
+
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+var (
+	// ErrPermanent is matched by the errors of probes that retrying won't
+	// fix, such as a hostname that doesn't exist or rejected credentials.
+	// Use Permanent to mark an error as permanent.
+	ErrPermanent = errors.New("permanent error")
+
+	// ErrAuthentication is wrapped by the errors of probes whose
+	// credentials were rejected by the server. These errors are also
+	// permanent.
+	ErrAuthentication = errors.New("authentication failed")
+)
+
+// permanentError is an error that matches ErrPermanent, keeping the
+// message and the chain of the original error.
+type permanentError struct {
+	err error
+}
+
+// Error returns the message of the original error.
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the original error.
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Is reports whether the target is ErrPermanent.
+func (e *permanentError) Is(target error) bool {
+	return target == ErrPermanent
+}
+
+// Permanent marks err as permanent, so the host isn't retried, without
+// changing its message. It returns nil if err is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err is an error that retrying won't fix,
+// either marked with Permanent or caused by rejected credentials.
+func IsPermanent(err error) bool {
+	return errors.Is(err, ErrPermanent) || errors.Is(err, ErrAuthentication)
+}
+
+// authError wraps err so it matches ErrAuthentication and ErrPermanent,
+// keeping the original error in the chain.
+func authError(err error) error {
+	return Permanent(fmt.Errorf("%w: %w", ErrAuthentication, err))
+}
+
+// isHostNotFound reports whether err was caused by a hostname that doesn't
+// exist, as opposed to a DNS server that's unreachable or timing out.
+func isHostNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+// dialError marks err as permanent if the host couldn't be resolved
+// because it doesn't exist.
+func dialError(err error) error {
+	if isHostNotFound(err) {
+		return Permanent(err)
+	}
+
+	return err
+}
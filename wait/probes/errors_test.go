@@ -0,0 +1,70 @@
+package probes
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+)
+
+func TestPermanent(t *testing.T) {
+	if Permanent(nil) != nil {
+		t.Errorf("Permanent(nil) != nil")
+	}
+
+	cause := errors.New("database does not exist")
+	err := Permanent(fmt.Errorf("error opening connection: %w", cause))
+
+	if got, want := err.Error(), "error opening connection: database does not exist"; got != want {
+		t.Errorf("Permanent().Error() = %q, want %q", got, want)
+	}
+
+	if !errors.Is(err, ErrPermanent) || !errors.Is(err, cause) {
+		t.Errorf("Permanent() = %v, want it to match ErrPermanent and the cause", err)
+	}
+}
+
+func TestIsPermanent(t *testing.T) {
+	notFound := &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "db.invalid", IsNotFound: true}}
+	timeout := &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "i/o timeout", Name: "db.example.com", IsTimeout: true}}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "Connection refused",
+			err:  errors.New("connection refused"),
+			want: false,
+		},
+		{
+			name: "Authentication",
+			err:  authError(errors.New("access denied")),
+			want: true,
+		},
+		{
+			name: "Wrapped authentication",
+			err:  fmt.Errorf("%w: access denied", ErrAuthentication),
+			want: true,
+		},
+		{
+			name: "Host not found",
+			err:  dialError(notFound),
+			want: true,
+		},
+		{
+			name: "DNS timeout",
+			err:  dialError(timeout),
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsPermanent(tt.err); got != tt.want {
+				t.Errorf("IsPermanent(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
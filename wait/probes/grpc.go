@@ -0,0 +1,114 @@
+package probes
+
+import (
+	"cmp"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCPinger is a pinger for gRPC servers implementing the standard health
+// checking protocol. It calls grpc.health.v1.Health/Check and only
+// considers the server up when it replies with SERVING.
+type GRPCPinger struct {
+	Host    string
+	Service string
+	TLS     *tls.Config
+	Timeout time.Duration
+}
+
+// Bootstrap sets up the pinger with the gRPC URL.
+// Expected URL format: grpc://host:port or grpcs://host:port
+func (g *GRPCPinger) Bootstrap(host string, opts Options) error {
+	if err := opts.checkParams(append([]string{"service"}, tlsParams...)...); err != nil {
+		return err
+	}
+
+	u, err := url.Parse(host)
+	if err != nil {
+		return fmt.Errorf("failed to parse host %q: %v", host, err)
+	}
+
+	if !oneOf(u.Scheme, "grpc", "grpcs") {
+		return fmt.Errorf("invalid scheme for grpc probe: %s", u.Scheme)
+	}
+
+	if u.Hostname() == "" {
+		return fmt.Errorf("no host specified for %s scheme", u.Scheme)
+	}
+
+	if u.Port() == "" {
+		return fmt.Errorf("no port specified for %s scheme", u.Scheme)
+	}
+
+	if strings.TrimPrefix(u.Path, "/") != "" {
+		return fmt.Errorf("unexpected path %q for grpc probe: use the service option to check a service", u.Path)
+	}
+
+	g.Host = u.Host
+
+	// An empty service name checks the health of the whole server.
+	g.Service = opts.Params.Get("service")
+
+	g.TLS = nil
+	if u.Scheme == "grpcs" {
+		g.TLS, err = tlsConfigFromOptions(opts, u.Hostname())
+		if err != nil {
+			return err
+		}
+	} else if hasTLSParams(opts) {
+		return fmt.Errorf("TLS options are only supported with the grpcs scheme")
+	}
+
+	g.Timeout = opts.attemptTimeout()
+	return nil
+}
+
+// Ping connects to the server and checks the health of the service.
+func (g *GRPCPinger) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, cmp.Or(g.Timeout, DefaultAttemptTimeout))
+	defer cancel()
+
+	creds := insecure.NewCredentials()
+	if g.TLS != nil {
+		creds = credentials.NewTLS(g.TLS)
+	}
+
+	// The host is dialed as is, without resolving it with gRPC's own
+	// resolver, so every attempt starts from a fresh connection.
+	conn, err := grpc.NewClient("passthrough:///"+g.Host, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return fmt.Errorf("unable to create grpc client: %w", err)
+	}
+	defer conn.Close()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: g.Service})
+	if err != nil {
+		// A server without the health service won't implement it later.
+		if status.Code(err) == codes.Unimplemented {
+			return Permanent(fmt.Errorf("server doesn't implement the grpc health checking protocol: %w", err))
+		}
+
+		return fmt.Errorf("grpc health check failed: %w", err)
+	}
+
+	if s := resp.GetStatus(); s != healthpb.HealthCheckResponse_SERVING {
+		if g.Service == "" {
+			return fmt.Errorf("server is %s", s)
+		}
+
+		return fmt.Errorf("service %q is %s", g.Service, s)
+	}
+
+	return nil
+}
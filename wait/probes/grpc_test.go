@@ -0,0 +1,222 @@
+package probes
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// startGRPCServer starts a gRPC server, over TLS if a certificate is given,
+// optionally registering the health service, and returns its address.
+func startGRPCServer(t *testing.T, healthServer *health.Server, cert *tls.Certificate) string {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+
+	var opts []grpc.ServerOption
+	if cert != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(&tls.Config{Certificates: []tls.Certificate{*cert}})))
+	}
+
+	srv := grpc.NewServer(opts...)
+	if healthServer != nil {
+		healthpb.RegisterHealthServer(srv, healthServer)
+	}
+
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	return lis.Addr().String()
+}
+
+func TestGRPCPinger_Bootstrap(t *testing.T) {
+	tests := []struct {
+		name        string
+		urlStr      string
+		opts        Options
+		wantHost    string
+		wantService string
+		wantTLS     bool
+		wantErr     bool
+	}{
+		{
+			name:     "Valid URL",
+			urlStr:   "grpc://localhost:50051",
+			wantHost: "localhost:50051",
+		},
+		{
+			name:        "Service and TLS options",
+			urlStr:      "grpcs://api.internal:443",
+			opts:        Options{Params: url.Values{"service": {"orders.v1.Orders"}, "server_name": {"api.example.com"}}},
+			wantHost:    "api.internal:443",
+			wantService: "orders.v1.Orders",
+			wantTLS:     true,
+		},
+		{
+			name:    "TLS options without TLS",
+			urlStr:  "grpc://localhost:50051",
+			opts:    Options{Params: url.Values{"insecure": {"true"}}},
+			wantErr: true,
+		},
+		{
+			name:    "Service in the path",
+			urlStr:  "grpc://localhost:50051/orders.v1.Orders",
+			wantErr: true,
+		},
+		{
+			name:    "No port specified",
+			urlStr:  "grpc://localhost",
+			wantErr: true,
+		},
+		{
+			name:    "No host specified",
+			urlStr:  "grpc://",
+			wantErr: true,
+		},
+		{
+			name:    "Unknown option",
+			urlStr:  "grpc://localhost:50051",
+			opts:    Options{Params: url.Values{"method": {"Check"}}},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid scheme",
+			urlStr:  "http://localhost:50051",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pinger := &GRPCPinger{}
+			err := pinger.Bootstrap(tt.urlStr, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GRPCPinger.Bootstrap() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if pinger.Host != tt.wantHost {
+				t.Errorf("GRPCPinger.Host = %q, want %q", pinger.Host, tt.wantHost)
+			}
+
+			if pinger.Service != tt.wantService {
+				t.Errorf("GRPCPinger.Service = %q, want %q", pinger.Service, tt.wantService)
+			}
+
+			if (pinger.TLS != nil) != tt.wantTLS {
+				t.Errorf("GRPCPinger.TLS = %v, want TLS %v", pinger.TLS, tt.wantTLS)
+			}
+		})
+	}
+}
+
+func TestGRPCPinger_Ping(t *testing.T) {
+	hs := health.NewServer()
+	hs.SetServingStatus("orders.v1.Orders", healthpb.HealthCheckResponse_SERVING)
+	hs.SetServingStatus("payments.v1.Payments", healthpb.HealthCheckResponse_NOT_SERVING)
+	addr := startGRPCServer(t, hs, nil)
+
+	notServing := health.NewServer()
+	notServing.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	notServingAddr := startGRPCServer(t, notServing, nil)
+
+	noHealthAddr := startGRPCServer(t, nil, nil)
+
+	cert, err := generateSelfSignedCert()
+	if err != nil {
+		t.Fatalf("Failed to generate self-signed certificate: %v", err)
+	}
+	tlsAddr := startGRPCServer(t, health.NewServer(), &cert)
+
+	tests := []struct {
+		name          string
+		pinger        *GRPCPinger
+		wantErr       string
+		wantPermanent bool
+	}{
+		{
+			name:   "Server serving",
+			pinger: &GRPCPinger{Host: addr},
+		},
+		{
+			name:   "Service serving",
+			pinger: &GRPCPinger{Host: addr, Service: "orders.v1.Orders"},
+		},
+		{
+			name:    "Service not serving",
+			pinger:  &GRPCPinger{Host: addr, Service: "payments.v1.Payments"},
+			wantErr: `service "payments.v1.Payments" is NOT_SERVING`,
+		},
+		{
+			name:    "Unknown service",
+			pinger:  &GRPCPinger{Host: addr, Service: "refunds.v1.Refunds"},
+			wantErr: "NotFound",
+		},
+		{
+			name:    "Server not serving",
+			pinger:  &GRPCPinger{Host: notServingAddr},
+			wantErr: "server is NOT_SERVING",
+		},
+		{
+			name:          "No health service",
+			pinger:        &GRPCPinger{Host: noHealthAddr},
+			wantErr:       "doesn't implement the grpc health checking protocol",
+			wantPermanent: true,
+		},
+		{
+			name:   "TLS",
+			pinger: &GRPCPinger{Host: tlsAddr, TLS: &tls.Config{InsecureSkipVerify: true}},
+		},
+		{
+			name:    "TLS with untrusted certificate",
+			pinger:  &GRPCPinger{Host: tlsAddr, TLS: &tls.Config{ServerName: "127.0.0.1"}},
+			wantErr: "certificate",
+		},
+		{
+			name:    "Plaintext against TLS",
+			pinger:  &GRPCPinger{Host: tlsAddr},
+			wantErr: "grpc health check failed",
+		},
+		{
+			name:    "Invalid host",
+			pinger:  &GRPCPinger{Host: "127.0.0.1:1"},
+			wantErr: "grpc health check failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			err := tt.pinger.Ping(ctx)
+			if (err != nil) != (tt.wantErr != "") {
+				t.Fatalf("GRPCPinger.Ping() error = %v, wantErr %q", err, tt.wantErr)
+			}
+
+			if err != nil && !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("GRPCPinger.Ping() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+
+			if errors.Is(err, ErrPermanent) != tt.wantPermanent {
+				t.Errorf("GRPCPinger.Ping() error = %v, wantPermanent %v", err, tt.wantPermanent)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 )
@@ -29,25 +30,63 @@ func unwrapError(err error) error {
 	return err
 }
 
-// doGet performs a GET request to the given URL with the provided client
-// and context, then checks the status code to ensure it is in the 2xx range.
-func doGet(ctx context.Context, client *http.Client, url string) error {
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+// doRequest performs the request to the given URL with the provided client
+// and context, then checks the status code to ensure it is one of the
+// expected ones, and the body against the body matchers, if any.
+func doRequest(ctx context.Context, client *http.Client, url string, r httpRequest) error {
+	var body io.Reader
+	if r.body != "" {
+		body = strings.NewReader(r.body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.method, url, body)
 	if err != nil {
 		return fmt.Errorf("error creating request: %w", err)
 	}
 
+	for name, values := range r.header {
+		req.Header[name] = values
+	}
+
+	// The Host header has to be set on the request itself to be honored.
+	if host := r.header.Get("Host"); host != "" {
+		req.Host = host
+	}
+
 	resp, err := client.Do(req)
 	if err != nil {
+		if isHostNotFound(err) {
+			return Permanent(unwrapError(err))
+		}
+
 		return unwrapError(err)
 	}
-	resp.Body.Close()
+	defer resp.Body.Close()
+
+	if !r.status.contains(resp.StatusCode) {
+		if r.status.String() == defaultStatus {
+			return fmt.Errorf("received non-2xx status code: %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+		}
+
+		return fmt.Errorf("received unexpected status code: %d %s (expected %s)", resp.StatusCode, http.StatusText(resp.StatusCode), r.status)
+	}
+
+	if !r.hasBodyMatchers() {
+		return nil
+	}
+
+	// Read one byte past the limit to know if the body was truncated.
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, r.maxBodySize+1))
+	if err != nil {
+		return fmt.Errorf("error reading response body: %w", unwrapError(err))
+	}
 
-	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		return fmt.Errorf("received non-2xx status code: %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	truncated := int64(len(respBody)) > r.maxBodySize
+	if truncated {
+		respBody = respBody[:r.maxBodySize]
 	}
 
-	return nil
+	return r.checkBody(respBody, truncated)
 }
 
 // extractProtocol extracts the protocol from the host string.
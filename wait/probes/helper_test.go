@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 )
 
@@ -112,11 +114,12 @@ func TestUnwrapError(t *testing.T) {
 	}
 }
 
-func TestDoGet(t *testing.T) {
+func TestDoRequest(t *testing.T) {
 	tests := []struct {
 		name       string
 		url        string
 		statusCode int
+		params     url.Values
 		wantErr    bool
 		errMsg     string
 	}{
@@ -146,6 +149,28 @@ func TestDoGet(t *testing.T) {
 			wantErr:    true,
 			errMsg:     "received non-2xx status code: 500 Internal Server Error",
 		},
+		{
+			name:       "Custom status accepted",
+			url:        "/",
+			statusCode: http.StatusUnauthorized,
+			params:     url.Values{"status": {"200-299,401"}},
+			wantErr:    false,
+		},
+		{
+			name:       "Custom status rejected",
+			url:        "/",
+			statusCode: http.StatusOK,
+			params:     url.Values{"status": {"204"}},
+			wantErr:    true,
+			errMsg:     "received unexpected status code: 200 OK (expected 204)",
+		},
+		{
+			name:       "Custom method, header and body",
+			url:        "/",
+			statusCode: http.StatusOK,
+			params:     url.Values{"method": {"post"}, "header": {"X-Probe: wait-for"}, "body": {"ping"}},
+			wantErr:    false,
+		},
 		{
 			name:    "Invalid URL",
 			url:     "http://[::1]:namedport",
@@ -156,8 +181,20 @@ func TestDoGet(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Create a test server that responds with the specified status code
+			req, err := httpRequestFromOptions(Options{Params: tt.params})
+			if err != nil {
+				t.Fatalf("httpRequestFromOptions() error = %v", err)
+			}
+
+			// Create a test server that responds with the specified status code,
+			// as long as the request matches the expected one
 			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if r.Method != req.method || string(body) != req.body || r.Header.Get("X-Probe") != req.header.Get("X-Probe") {
+					w.WriteHeader(http.StatusTeapot)
+					return
+				}
+
 				w.WriteHeader(tt.statusCode)
 			}))
 			defer server.Close()
@@ -170,13 +207,13 @@ func TestDoGet(t *testing.T) {
 				url = tt.url // Use the invalid URL directly
 			}
 
-			err := doGet(ctx, client, url)
+			err = doRequest(ctx, client, url, req)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("doGet() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("doRequest() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if err != nil && err.Error() != tt.errMsg {
-				t.Errorf("doGet() error = %v, errMsg %v", err, tt.errMsg)
+				t.Errorf("doRequest() error = %v, errMsg %v", err, tt.errMsg)
 			}
 		})
 	}
@@ -0,0 +1,195 @@
+package probes
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// httpParams are the options accepted by both the HTTP and HTTPS probes.
+var httpParams = []string{
+	"method", "header", "body", "status",
+	"body_contains", "body_regex", "body_json", "max_body_size",
+}
+
+// defaultStatus is the status expression used when none is provided.
+const defaultStatus = "200-299"
+
+// defaultMaxBodySize is the maximum amount of bytes read from the response
+// body to evaluate the body matchers, when no limit is provided.
+const defaultMaxBodySize = 1 << 20
+
+// httpRequest holds the request performed by the HTTP and HTTPS probes,
+// and the status codes and body matchers that make the probe succeed.
+type httpRequest struct {
+	method string
+	header http.Header
+	body   string
+	status statusRanges
+
+	bodyContains []string
+	bodyRegex    []*regexp.Regexp
+	bodyJSON     []*jsonAssertion
+	maxBodySize  int64
+}
+
+// hasBodyMatchers returns true if the response body has to be read and
+// checked for the probe to succeed.
+func (r httpRequest) hasBodyMatchers() bool {
+	return len(r.bodyContains) > 0 || len(r.bodyRegex) > 0 || len(r.bodyJSON) > 0
+}
+
+// checkBody validates the response body against every configured matcher.
+// When the body was larger than the maximum size, only its beginning is
+// available, which is enough for the text matchers but not for JSON.
+func (r httpRequest) checkBody(body []byte, truncated bool) error {
+	for _, s := range r.bodyContains {
+		if !bytes.Contains(body, []byte(s)) {
+			return fmt.Errorf("response body does not contain %q", s)
+		}
+	}
+
+	for _, re := range r.bodyRegex {
+		if !re.Match(body) {
+			return fmt.Errorf("response body does not match regular expression %q", re)
+		}
+	}
+
+	if len(r.bodyJSON) > 0 && truncated {
+		return fmt.Errorf("response body is larger than %d bytes, unable to evaluate JSON path", r.maxBodySize)
+	}
+
+	for _, a := range r.bodyJSON {
+		if err := a.check(body); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// httpRequestFromOptions builds the request from the probe options, using
+// a GET request that expects a 2xx status code by default.
+func httpRequestFromOptions(opts Options) (httpRequest, error) {
+	req := httpRequest{
+		method: strings.ToUpper(opts.Params.Get("method")),
+		header: make(http.Header),
+		body:   opts.Params.Get("body"),
+	}
+
+	if req.method == "" {
+		req.method = http.MethodGet
+	}
+
+	for _, h := range opts.Params["header"] {
+		name, value, ok := strings.Cut(h, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return httpRequest{}, fmt.Errorf("invalid header %q: must be in the format \"Name: value\"", h)
+		}
+
+		req.header.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+
+	status := opts.Params.Get("status")
+	if status == "" {
+		status = defaultStatus
+	}
+
+	var err error
+	req.status, err = parseStatusRanges(status)
+	if err != nil {
+		return httpRequest{}, err
+	}
+
+	req.bodyContains = opts.Params["body_contains"]
+
+	for _, expr := range opts.Params["body_regex"] {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return httpRequest{}, fmt.Errorf("invalid body_regex %q: %w", expr, err)
+		}
+		req.bodyRegex = append(req.bodyRegex, re)
+	}
+
+	for _, expr := range opts.Params["body_json"] {
+		a, err := parseJSONAssertion(expr)
+		if err != nil {
+			return httpRequest{}, err
+		}
+		req.bodyJSON = append(req.bodyJSON, a)
+	}
+
+	req.maxBodySize = defaultMaxBodySize
+	if v := opts.Params.Get("max_body_size"); v != "" {
+		req.maxBodySize, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || req.maxBodySize <= 0 {
+			return httpRequest{}, fmt.Errorf("invalid max_body_size %q: must be a positive number of bytes", v)
+		}
+	}
+
+	return req, nil
+}
+
+// statusRange is an inclusive range of HTTP status codes.
+type statusRange struct {
+	from, to int
+}
+
+// statusRanges is a list of accepted HTTP status codes, built from an
+// expression such as "200-299,401".
+type statusRanges []statusRange
+
+// parseStatusRanges parses a comma-separated list of status codes or
+// inclusive ranges of status codes.
+func parseStatusRanges(expr string) (statusRanges, error) {
+	var ranges statusRanges
+
+	for _, part := range strings.Split(expr, ",") {
+		part = strings.TrimSpace(part)
+		from, to, isRange := strings.Cut(part, "-")
+		if !isRange {
+			to = from
+		}
+
+		var r statusRange
+		var errFrom, errTo error
+		r.from, errFrom = strconv.Atoi(strings.TrimSpace(from))
+		r.to, errTo = strconv.Atoi(strings.TrimSpace(to))
+
+		if errFrom != nil || errTo != nil || r.from < 100 || r.to > 599 || r.from > r.to {
+			return nil, fmt.Errorf("invalid status code expression %q: %q is not a valid status code or range", expr, part)
+		}
+
+		ranges = append(ranges, r)
+	}
+
+	return ranges, nil
+}
+
+// contains returns true if the status code is within any of the ranges.
+func (s statusRanges) contains(code int) bool {
+	for _, r := range s {
+		if code >= r.from && code <= r.to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String returns the expression representation of the ranges.
+func (s statusRanges) String() string {
+	parts := make([]string, 0, len(s))
+	for _, r := range s {
+		if r.from == r.to {
+			parts = append(parts, strconv.Itoa(r.from))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", r.from, r.to))
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
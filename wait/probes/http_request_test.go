@@ -0,0 +1,219 @@
+package probes
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestParseStatusRanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		want     string
+		accepted []int
+		rejected []int
+		wantErr  bool
+	}{
+		{
+			name:     "Single range",
+			expr:     "200-299",
+			want:     "200-299",
+			accepted: []int{200, 204, 299},
+			rejected: []int{199, 300, 404},
+		},
+		{
+			name:     "Range and single codes",
+			expr:     "200-299, 401,404",
+			want:     "200-299,401,404",
+			accepted: []int{200, 401, 404},
+			rejected: []int{400, 403, 500},
+		},
+		{
+			name:    "Not a number",
+			expr:    "ok",
+			wantErr: true,
+		},
+		{
+			name:    "Reversed range",
+			expr:    "299-200",
+			wantErr: true,
+		},
+		{
+			name:    "Out of bounds",
+			expr:    "200-600",
+			wantErr: true,
+		},
+		{
+			name:    "Empty element",
+			expr:    "200,",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseStatusRanges(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseStatusRanges() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if got.String() != tt.want {
+				t.Errorf("parseStatusRanges().String() = %q, want %q", got.String(), tt.want)
+			}
+
+			for _, code := range tt.accepted {
+				if !got.contains(code) {
+					t.Errorf("contains(%d) = false, want true", code)
+				}
+			}
+
+			for _, code := range tt.rejected {
+				if got.contains(code) {
+					t.Errorf("contains(%d) = true, want false", code)
+				}
+			}
+		})
+	}
+}
+
+func TestHTTPRequestFromOptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		params     url.Values
+		wantMethod string
+		wantHeader string
+		wantErr    bool
+	}{
+		{
+			name:       "Defaults",
+			wantMethod: "GET",
+		},
+		{
+			name:       "Custom method and header",
+			params:     url.Values{"method": {"head"}, "header": {"Authorization: Bearer token"}},
+			wantMethod: "HEAD",
+			wantHeader: "Bearer token",
+		},
+		{
+			name:    "Invalid header",
+			params:  url.Values{"header": {"Authorization"}},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid status",
+			params:  url.Values{"status": {"2xx"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := httpRequestFromOptions(Options{Params: tt.params})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("httpRequestFromOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if got.method != tt.wantMethod {
+				t.Errorf("method = %q, want %q", got.method, tt.wantMethod)
+			}
+
+			if h := got.header.Get("Authorization"); h != tt.wantHeader {
+				t.Errorf("Authorization header = %q, want %q", h, tt.wantHeader)
+			}
+		})
+	}
+}
+
+func TestHTTPRequestCheckBody(t *testing.T) {
+	body := []byte(`{"status":"UP","version":"1.2.3"}`)
+
+	tests := []struct {
+		name      string
+		params    url.Values
+		truncated bool
+		wantErr   bool
+	}{
+		{
+			name:   "No matchers",
+			params: url.Values{},
+		},
+		{
+			name:   "Contains",
+			params: url.Values{"body_contains": {`"UP"`, "version"}},
+		},
+		{
+			name:    "Does not contain",
+			params:  url.Values{"body_contains": {"DOWN"}},
+			wantErr: true,
+		},
+		{
+			name:   "Regular expression",
+			params: url.Values{"body_regex": {`"version":"1\.\d+\.\d+"`}},
+		},
+		{
+			name:    "Regular expression not matching",
+			params:  url.Values{"body_regex": {`"version":"2\.`}},
+			wantErr: true,
+		},
+		{
+			name:   "JSON path",
+			params: url.Values{"body_json": {`$.status == "UP"`}},
+		},
+		{
+			name:    "JSON path not matching",
+			params:  url.Values{"body_json": {`$.status == "STARTING"`}},
+			wantErr: true,
+		},
+		{
+			name:      "Truncated body with text matcher",
+			params:    url.Values{"body_contains": {"UP"}},
+			truncated: true,
+		},
+		{
+			name:      "Truncated body with JSON path",
+			params:    url.Values{"body_json": {`$.status == "UP"`}},
+			truncated: true,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := httpRequestFromOptions(Options{Params: tt.params})
+			if err != nil {
+				t.Fatalf("httpRequestFromOptions() error = %v", err)
+			}
+
+			if err := req.checkBody(body, tt.truncated); (err != nil) != tt.wantErr {
+				t.Errorf("checkBody() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHTTPRequestFromOptions_BodyMatchers(t *testing.T) {
+	tests := []struct {
+		name   string
+		params url.Values
+	}{
+		{name: "Invalid regular expression", params: url.Values{"body_regex": {"("}}},
+		{name: "Invalid JSON path", params: url.Values{"body_json": {"status"}}},
+		{name: "Invalid max body size", params: url.Values{"max_body_size": {"0"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := httpRequestFromOptions(Options{Params: tt.params}); err == nil {
+				t.Errorf("httpRequestFromOptions() error = nil, want error")
+			}
+		})
+	}
+}
@@ -3,11 +3,9 @@ package probes
 
 import (
 	"context"
-	"crypto/tls"
 	"fmt"
 	"net/http"
 	"net/url"
-	"time"
 )
 
 // validateURL checks if the URL is valid. There are no checks
@@ -33,10 +31,15 @@ func validateURL(scheme string, u *url.URL) error {
 type HTTPSPinger struct {
 	url        *url.URL
 	httpClient *http.Client
+	request    httpRequest
 }
 
 // Bootstrap sets up the pinger with the HTTPS URL.
-func (h *HTTPSPinger) Bootstrap(host string) error {
+func (h *HTTPSPinger) Bootstrap(host string, opts Options) error {
+	if err := opts.checkParams(append(httpParams, tlsParams...)...); err != nil {
+		return err
+	}
+
 	u, err := url.Parse(host)
 	if err != nil {
 		return fmt.Errorf("failed to parse host %q: %v", host, err)
@@ -48,34 +51,46 @@ func (h *HTTPSPinger) Bootstrap(host string) error {
 
 	h.url = u
 
+	h.request, err = httpRequestFromOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	// TLS certificate verification is enabled unless explicitly disabled
+	tlsConfig, err := tlsConfigFromOptions(opts, u.Hostname())
+	if err != nil {
+		return err
+	}
+
 	// Initialize HTTPS client with timeout and TLS configuration
 	h.httpClient = &http.Client{
-		Timeout: 1 * time.Second, // 1 second timeout per request
-
-		// Ensure TLS certificate verification is enabled
+		Timeout: opts.attemptTimeout(), // timeout per request
 		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: false, // Do not skip TLS verification
-			},
+			TLSClientConfig: tlsConfig,
 		},
 	}
 
 	return nil
 }
 
-// Ping performs an HTTPS GET request and checks the status code.
+// Ping performs the HTTPS request and checks the status code.
 func (h *HTTPSPinger) Ping(ctx context.Context) error {
-	return doGet(ctx, h.httpClient, h.url.String())
+	return doRequest(ctx, h.httpClient, h.url.String(), h.request)
 }
 
 // HTTPPinger is a pinger for HTTP connections.
 type HTTPPinger struct {
 	url        *url.URL
 	HTTPClient *http.Client
+	request    httpRequest
 }
 
 // Bootstrap sets up the pinger with the HTTP URL.
-func (h *HTTPPinger) Bootstrap(host string) error {
+func (h *HTTPPinger) Bootstrap(host string, opts Options) error {
+	if err := opts.checkParams(httpParams...); err != nil {
+		return err
+	}
+
 	u, err := url.Parse(host)
 	if err != nil {
 		return fmt.Errorf("failed to parse host %q: %v", host, err)
@@ -87,15 +102,20 @@ func (h *HTTPPinger) Bootstrap(host string) error {
 
 	h.url = u
 
+	h.request, err = httpRequestFromOptions(opts)
+	if err != nil {
+		return err
+	}
+
 	// Initialize HTTP client with timeout for each request
 	h.HTTPClient = &http.Client{
-		Timeout: 1 * time.Second, // 1 second timeout per request
+		Timeout: opts.attemptTimeout(), // timeout per request
 	}
 
 	return nil
 }
 
-// Ping performs an HTTP GET request and checks the status code.
+// Ping performs the HTTP request and checks the status code.
 func (h *HTTPPinger) Ping(ctx context.Context) error {
-	return doGet(ctx, h.HTTPClient, h.url.String())
+	return doRequest(ctx, h.HTTPClient, h.url.String(), h.request)
 }
@@ -13,6 +13,8 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 )
@@ -43,7 +45,7 @@ func TestHTTPPinger_Bootstrap(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			pinger := &HTTPPinger{}
-			if err := pinger.Bootstrap(tt.urlStr); (err != nil) != tt.wantErr {
+			if err := pinger.Bootstrap(tt.urlStr, Options{}); (err != nil) != tt.wantErr {
 				t.Errorf("HTTPPinger.Bootstrap() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -77,7 +79,7 @@ func TestHTTPPinger_Ping(t *testing.T) {
 			defer ts.Close()
 
 			pinger := &HTTPPinger{}
-			if err := pinger.Bootstrap(ts.URL); err != nil {
+			if err := pinger.Bootstrap(ts.URL, Options{}); err != nil {
 				t.Fatalf("HTTPPinger.Bootstrap() error = %v", err)
 			}
 
@@ -91,6 +93,80 @@ func TestHTTPPinger_Ping(t *testing.T) {
 	}
 }
 
+func TestHTTPPinger_AttemptTimeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		wantErr bool
+	}{
+		{
+			name:    "Attempt timeout shorter than response",
+			timeout: 50 * time.Millisecond,
+			wantErr: true,
+		},
+		{
+			name:    "Attempt timeout longer than response",
+			timeout: 2 * time.Second,
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pinger := &HTTPPinger{}
+			if err := pinger.Bootstrap(ts.URL, Options{AttemptTimeout: tt.timeout}); err != nil {
+				t.Fatalf("HTTPPinger.Bootstrap() error = %v", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if err := pinger.Ping(ctx); (err != nil) != tt.wantErr {
+				t.Errorf("HTTPPinger.Ping() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHTTPPinger_BodyMatchers(t *testing.T) {
+	status := "starting"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"` + status + `"}` + strings.Repeat(" ", 64)))
+	}))
+	defer ts.Close()
+
+	pinger := &HTTPPinger{}
+	if err := pinger.Bootstrap(ts.URL, Options{
+		Params: url.Values{"body_json": {`$.status == "UP"`}},
+	}); err != nil {
+		t.Fatalf("HTTPPinger.Bootstrap() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := pinger.Ping(ctx); err == nil {
+		t.Errorf("HTTPPinger.Ping() error = nil, want error while starting")
+	}
+
+	status = "UP"
+	if err := pinger.Ping(ctx); err != nil {
+		t.Errorf("HTTPPinger.Ping() error = %v, want nil once up", err)
+	}
+
+	// A body larger than the limit cannot be evaluated as JSON.
+	pinger.request.maxBodySize = 16
+	if err := pinger.Ping(ctx); err == nil {
+		t.Errorf("HTTPPinger.Ping() error = nil, want error for large body")
+	}
+}
+
 func generateSelfSignedCert() (tls.Certificate, error) {
 	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	if err != nil {
@@ -160,7 +236,7 @@ func TestHTTPSPinger_Bootstrap(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			pinger := &HTTPSPinger{}
-			if err := pinger.Bootstrap(tt.urlStr); (err != nil) != tt.wantErr {
+			if err := pinger.Bootstrap(tt.urlStr, Options{}); (err != nil) != tt.wantErr {
 				t.Errorf("HTTPSPinger.Bootstrap() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -203,7 +279,7 @@ func TestHTTPSPinger_Ping(t *testing.T) {
 			caCertPool.AppendCertsFromPEM(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}))
 
 			pinger := &HTTPSPinger{}
-			if err := pinger.Bootstrap(ts.URL); err != nil {
+			if err := pinger.Bootstrap(ts.URL, Options{}); err != nil {
 				t.Fatalf("HTTPSPinger.Bootstrap() error = %v", err)
 			}
 
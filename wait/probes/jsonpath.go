@@ -0,0 +1,168 @@
+package probes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// jsonAssertion checks the value found at a JSON path in a document. The
+// supported syntax is a subset of JSONPath: a root "$" followed by any
+// number of ".field", "['field']" or "[index]" selectors, optionally
+// followed by "==" and the expected value as a JSON literal, such as
+// `$.status == "UP"` or `$.checks[0].healthy == true`. Without an expected
+// value, the assertion only requires the path to exist and not be null.
+type jsonAssertion struct {
+	expr     string
+	path     []any // string for fields, int for indexes
+	expected any
+	exact    bool
+}
+
+// parseJSONAssertion parses an expression such as `$.status == "UP"`.
+func parseJSONAssertion(expr string) (*jsonAssertion, error) {
+	a := &jsonAssertion{expr: expr}
+
+	pathExpr, literal, hasExpected := strings.Cut(expr, "==")
+	pathExpr = strings.TrimSpace(pathExpr)
+
+	if hasExpected {
+		dec := json.NewDecoder(strings.NewReader(literal))
+		dec.UseNumber()
+		if err := dec.Decode(&a.expected); err != nil {
+			return nil, fmt.Errorf("invalid expected value in JSON path expression %q: %w", expr, err)
+		}
+		a.exact = true
+	}
+
+	path, err := parseJSONPath(pathExpr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON path expression %q: %w", expr, err)
+	}
+	a.path = path
+
+	return a, nil
+}
+
+// parseJSONPath splits a path such as "$.checks[0]['name']" into its
+// field names and indexes.
+func parseJSONPath(expr string) ([]any, error) {
+	rest, ok := strings.CutPrefix(expr, "$")
+	if !ok {
+		return nil, fmt.Errorf("path must start with \"$\"")
+	}
+
+	var path []any
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+
+			if end == 0 {
+				return nil, fmt.Errorf("empty field name")
+			}
+
+			path = append(path, rest[:end])
+			rest = rest[end:]
+
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated \"[\"")
+			}
+
+			selector := rest[1:end]
+			rest = rest[end+1:]
+
+			if len(selector) >= 2 && oneOf(selector[0], '\'', '"') && selector[len(selector)-1] == selector[0] {
+				path = append(path, selector[1:len(selector)-1])
+				continue
+			}
+
+			index, err := strconv.Atoi(selector)
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid index %q", selector)
+			}
+
+			path = append(path, index)
+
+		default:
+			return nil, fmt.Errorf("unexpected character %q", rest[0])
+		}
+	}
+
+	return path, nil
+}
+
+// check evaluates the assertion against the JSON document.
+func (a *jsonAssertion) check(doc []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+
+	var current any
+	if err := dec.Decode(&current); err != nil {
+		return fmt.Errorf("response body is not valid JSON: %w", err)
+	}
+
+	for _, step := range a.path {
+		switch step := step.(type) {
+		case string:
+			obj, ok := current.(map[string]any)
+			if !ok {
+				return fmt.Errorf("JSON path %q not found in response body", a.expr)
+			}
+
+			if current, ok = obj[step]; !ok {
+				return fmt.Errorf("JSON path %q not found in response body", a.expr)
+			}
+
+		case int:
+			arr, ok := current.([]any)
+			if !ok || step >= len(arr) {
+				return fmt.Errorf("JSON path %q not found in response body", a.expr)
+			}
+
+			current = arr[step]
+		}
+	}
+
+	if !a.exact {
+		if current == nil {
+			return fmt.Errorf("JSON path %q is null in response body", a.expr)
+		}
+
+		return nil
+	}
+
+	if !jsonEqual(current, a.expected) {
+		got, _ := json.Marshal(current)
+		return fmt.Errorf("JSON path assertion %q failed: got %s", a.expr, got)
+	}
+
+	return nil
+}
+
+// jsonEqual compares two decoded JSON values, treating numbers as equal
+// if they represent the same value regardless of their formatting.
+func jsonEqual(a, b any) bool {
+	an, aok := a.(json.Number)
+	bn, bok := b.(json.Number)
+	if aok && bok {
+		af, aerr := an.Float64()
+		bf, berr := bn.Float64()
+		if aerr == nil && berr == nil {
+			return af == bf
+		}
+
+		return an == bn
+	}
+
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,96 @@
+package probes
+
+import "testing"
+
+func TestJSONAssertion(t *testing.T) {
+	doc := []byte(`{"status":"UP","checks":[{"name":"db","healthy":true,"latency":1.50}],"details":{"version":null,"a.b":2}}`)
+
+	tests := []struct {
+		name     string
+		expr     string
+		wantErr  bool
+		parseErr bool
+	}{
+		{
+			name: "String equality",
+			expr: `$.status == "UP"`,
+		},
+		{
+			name:    "String inequality",
+			expr:    `$.status == "DOWN"`,
+			wantErr: true,
+		},
+		{
+			name: "Nested index and boolean",
+			expr: `$.checks[0].healthy == true`,
+		},
+		{
+			name: "Number with different formatting",
+			expr: `$.checks[0].latency == 1.5`,
+		},
+		{
+			name: "Bracket field name",
+			expr: `$.details['a.b'] == 2`,
+		},
+		{
+			name: "Existence only",
+			expr: `$.checks[0].name`,
+		},
+		{
+			name:    "Null value without expected value",
+			expr:    `$.details.version`,
+			wantErr: true,
+		},
+		{
+			name: "Null value with expected null",
+			expr: `$.details.version == null`,
+		},
+		{
+			name:    "Missing field",
+			expr:    `$.missing == "UP"`,
+			wantErr: true,
+		},
+		{
+			name:    "Index out of range",
+			expr:    `$.checks[3].name`,
+			wantErr: true,
+		},
+		{
+			name:     "Missing root",
+			expr:     `status == "UP"`,
+			parseErr: true,
+		},
+		{
+			name:     "Invalid expected value",
+			expr:     `$.status == UP`,
+			parseErr: true,
+		},
+		{
+			name:     "Invalid index",
+			expr:     `$.checks[-1]`,
+			parseErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := parseJSONAssertion(tt.expr)
+			if (err != nil) != tt.parseErr {
+				t.Fatalf("parseJSONAssertion() error = %v, wantErr %v", err, tt.parseErr)
+			}
+
+			if tt.parseErr {
+				return
+			}
+
+			if err := a.check(doc); (err != nil) != tt.wantErr {
+				t.Errorf("check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	a, _ := parseJSONAssertion(`$.status == "UP"`)
+	if err := a.check([]byte("not json")); err == nil {
+		t.Errorf("check() error = nil, want invalid JSON error")
+	}
+}
@@ -0,0 +1,54 @@
+package probes
+
+import (
+	"cmp"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultAttemptTimeout is the maximum time a single attempt against a host
+// can take when no attempt timeout is configured.
+const DefaultAttemptTimeout = 1 * time.Second
+
+// Options holds the settings passed to every probe on Bootstrap.
+type Options struct {
+	// AttemptTimeout is the maximum time a single attempt can take. If
+	// zero, DefaultAttemptTimeout is used.
+	AttemptTimeout time.Duration
+
+	// Params holds the probe-specific parameters, keyed by name.
+	Params url.Values
+}
+
+// attemptTimeout returns the configured attempt timeout, or the default
+// one if none was configured.
+func (o Options) attemptTimeout() time.Duration {
+	return cmp.Or(o.AttemptTimeout, DefaultAttemptTimeout)
+}
+
+// checkParams returns an error if any of the provided parameters is not
+// one of the allowed names, to catch typos early.
+func (o Options) checkParams(allowed ...string) error {
+	var unknown []string
+	for k := range o.Params {
+		if !slices.Contains(allowed, k) {
+			unknown = append(unknown, k)
+		}
+	}
+
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	sort.Strings(unknown)
+
+	if len(allowed) == 0 {
+		return fmt.Errorf("unknown options %s: this probe accepts no options", strings.Join(unknown, ", "))
+	}
+
+	return fmt.Errorf("unknown options %s: accepted options are %s", strings.Join(unknown, ", "), strings.Join(allowed, ", "))
+}
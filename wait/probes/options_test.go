@@ -0,0 +1,60 @@
+package probes
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestOptionsCheckParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  url.Values
+		allowed []string
+		wantErr bool
+	}{
+		{
+			name:    "No params",
+			params:  nil,
+			allowed: nil,
+			wantErr: false,
+		},
+		{
+			name:    "Allowed params",
+			params:  url.Values{"method": {"POST"}},
+			allowed: []string{"method", "status"},
+			wantErr: false,
+		},
+		{
+			name:    "Unknown param",
+			params:  url.Values{"methd": {"POST"}},
+			allowed: []string{"method"},
+			wantErr: true,
+		},
+		{
+			name:    "Params on a probe without options",
+			params:  url.Values{"method": {"POST"}},
+			allowed: nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := Options{Params: tt.params}
+			if err := o.checkParams(tt.allowed...); (err != nil) != tt.wantErr {
+				t.Errorf("checkParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOptionsAttemptTimeout(t *testing.T) {
+	if got := (Options{}).attemptTimeout(); got != DefaultAttemptTimeout {
+		t.Errorf("attemptTimeout() = %s, want %s", got, DefaultAttemptTimeout)
+	}
+
+	if got := (Options{AttemptTimeout: 5 * time.Second}).attemptTimeout(); got != 5*time.Second {
+		t.Errorf("attemptTimeout() = %s, want %s", got, 5*time.Second)
+	}
+}
@@ -0,0 +1,287 @@
+package probes
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeRedisServer starts a minimal Redis server that requires the given
+// password (if any) and reports the given loading state.
+func fakeRedisServer(t *testing.T, password string, loading bool) string {
+	t.Helper()
+
+	srv, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+
+	go func() {
+		for {
+			conn, err := srv.Accept()
+			if err != nil {
+				return
+			}
+
+			go func(conn net.Conn) {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				authed := password == ""
+
+				for {
+					args, err := readFakeRedisCommand(r)
+					if err != nil {
+						return
+					}
+
+					switch strings.ToUpper(args[0]) {
+					case "AUTH":
+						if args[len(args)-1] != password {
+							io.WriteString(conn, "-WRONGPASS invalid username-password pair\r\n")
+							continue
+						}
+						authed = true
+						io.WriteString(conn, "+OK\r\n")
+					case "SELECT":
+						io.WriteString(conn, "+OK\r\n")
+					case "PING":
+						if !authed {
+							io.WriteString(conn, "-NOAUTH Authentication required.\r\n")
+							continue
+						}
+						io.WriteString(conn, "+PONG\r\n")
+					case "INFO":
+						state := 0
+						if loading {
+							state = 1
+						}
+						info := fmt.Sprintf("# Persistence\r\nloading:%d\r\n", state)
+						fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(info), info)
+					default:
+						io.WriteString(conn, "-ERR unknown command\r\n")
+					}
+				}
+			}(conn)
+		}
+	}()
+
+	return srv.Addr().String()
+}
+
+// fakeFullRedisServer starts a Redis server that has reached its maxclients
+// limit, so it replies to every new connection with an error and closes it.
+func fakeFullRedisServer(t *testing.T) string {
+	t.Helper()
+
+	srv, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+
+	go func() {
+		for {
+			conn, err := srv.Accept()
+			if err != nil {
+				return
+			}
+
+			io.WriteString(conn, "-ERR max number of clients reached\r\n")
+			conn.Close()
+		}
+	}()
+
+	return srv.Addr().String()
+}
+
+// readFakeRedisCommand reads a command sent as a RESP array of bulk strings.
+func readFakeRedisCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		arg, err := readRedisReply(r)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+
+	return args, nil
+}
+
+func TestRedisPinger_Bootstrap(t *testing.T) {
+	tests := []struct {
+		name     string
+		urlStr   string
+		opts     Options
+		wantHost string
+		wantDB   int
+		wantErr  bool
+	}{
+		{
+			name:     "Valid URL",
+			urlStr:   "redis://localhost:6380",
+			wantHost: "localhost:6380",
+		},
+		{
+			name:     "Default port",
+			urlStr:   "redis://localhost",
+			wantHost: "localhost:6379",
+		},
+		{
+			name:     "Password and database",
+			urlStr:   "rediss://:secret@localhost/2",
+			wantHost: "localhost:6379",
+			wantDB:   2,
+		},
+		{
+			name:    "Invalid database",
+			urlStr:  "redis://localhost/two",
+			wantErr: true,
+		},
+		{
+			name:    "Username without password",
+			urlStr:  "redis://user@localhost",
+			wantErr: true,
+		},
+		{
+			name:    "No host specified",
+			urlStr:  "redis://",
+			wantErr: true,
+		},
+		{
+			name:    "Invalid scheme",
+			urlStr:  "http://localhost:6379",
+			wantErr: true,
+		},
+		{
+			name:    "Invalid check_loading",
+			urlStr:  "redis://localhost",
+			opts:    Options{Params: map[string][]string{"check_loading": {"maybe"}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pinger := &RedisPinger{}
+			err := pinger.Bootstrap(tt.urlStr, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RedisPinger.Bootstrap() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if pinger.Host != tt.wantHost {
+				t.Errorf("RedisPinger.Host = %q, want %q", pinger.Host, tt.wantHost)
+			}
+
+			if pinger.DB != tt.wantDB {
+				t.Errorf("RedisPinger.DB = %d, want %d", pinger.DB, tt.wantDB)
+			}
+		})
+	}
+}
+
+func TestRedisPinger_Ping(t *testing.T) {
+	open := fakeRedisServer(t, "", false)
+	protected := fakeRedisServer(t, "secret", false)
+	loading := fakeRedisServer(t, "", true)
+	full := fakeFullRedisServer(t)
+
+	tests := []struct {
+		name      string
+		pinger    *RedisPinger
+		wantErr   bool
+		wantAuth  bool
+		wantRetry bool
+	}{
+		{
+			name:   "No authentication",
+			pinger: &RedisPinger{Host: open, DB: 1},
+		},
+		{
+			name:   "Valid password",
+			pinger: &RedisPinger{Host: protected, Password: "secret"},
+		},
+		{
+			name:   "Valid username and password",
+			pinger: &RedisPinger{Host: protected, Username: "app", Password: "secret"},
+		},
+		{
+			name:     "Invalid password",
+			pinger:   &RedisPinger{Host: protected, Password: "wrong"},
+			wantErr:  true,
+			wantAuth: true,
+		},
+		{
+			name:     "Missing password",
+			pinger:   &RedisPinger{Host: protected},
+			wantErr:  true,
+			wantAuth: true,
+		},
+		{
+			name:      "Max clients reached",
+			pinger:    &RedisPinger{Host: full, Password: "secret"},
+			wantErr:   true,
+			wantRetry: true,
+		},
+		{
+			name:   "Loading without check",
+			pinger: &RedisPinger{Host: loading},
+		},
+		{
+			name:    "Loading with check",
+			pinger:  &RedisPinger{Host: loading, CheckLoading: true},
+			wantErr: true,
+		},
+		{
+			name:   "Loaded with check",
+			pinger: &RedisPinger{Host: open, CheckLoading: true},
+		},
+		{
+			name:    "Invalid host",
+			pinger:  &RedisPinger{Host: "invalidhost:6379"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			err := tt.pinger.Ping(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RedisPinger.Ping() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if errors.Is(err, ErrAuthentication) != tt.wantAuth {
+				t.Errorf("RedisPinger.Ping() error = %v, wantAuth %v", err, tt.wantAuth)
+			}
+
+			if tt.wantRetry && IsPermanent(err) {
+				t.Errorf("RedisPinger.Ping() error = %v, want a retryable error", err)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package probes
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"net"
@@ -10,11 +11,16 @@ import (
 
 // TCPPinger is a pinger for TCP connections.
 type TCPPinger struct {
-	Host string
+	Host    string
+	Timeout time.Duration
 }
 
 // Bootstrap sets up the pinger with the URL.
-func (t *TCPPinger) Bootstrap(host string) error {
+func (t *TCPPinger) Bootstrap(host string, opts Options) error {
+	if err := opts.checkParams(); err != nil {
+		return err
+	}
+
 	if proto := extractProtocol(host); proto == "" {
 		host = "tcp://" + host
 	}
@@ -33,15 +39,16 @@ func (t *TCPPinger) Bootstrap(host string) error {
 	}
 
 	t.Host = u.Host
+	t.Timeout = opts.attemptTimeout()
 	return nil
 }
 
 // Ping attempts to connect to the host.
 func (t *TCPPinger) Ping(ctx context.Context) error {
-	d := net.Dialer{Timeout: 1 * time.Second}
+	d := net.Dialer{Timeout: cmp.Or(t.Timeout, DefaultAttemptTimeout)}
 	conn, err := d.DialContext(ctx, "tcp", t.Host)
 	if err != nil {
-		return err
+		return dialError(err)
 	}
 	conn.Close()
 	return nil
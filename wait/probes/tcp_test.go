@@ -38,7 +38,7 @@ func TestTCPPinger_Bootstrap(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			pinger := &TCPPinger{}
-			if err := pinger.Bootstrap(tt.urlStr); (err != nil) != tt.wantErr {
+			if err := pinger.Bootstrap(tt.urlStr, Options{}); (err != nil) != tt.wantErr {
 				t.Errorf("TCPPinger.Bootstrap() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
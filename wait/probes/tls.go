@@ -0,0 +1,146 @@
+package probes
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// tlsParams are the options accepted by every probe that connects over TLS.
+var tlsParams = []string{
+	"ca_file", "ca_dir", "cert_file", "key_file",
+	"server_name", "min_tls_version", "insecure",
+}
+
+// tlsVersions maps the accepted values of "min_tls_version" to their
+// TLS version.
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// hasTLSParams returns true if any of the TLS options was provided.
+func hasTLSParams(opts Options) bool {
+	for _, p := range tlsParams {
+		if opts.Params.Has(p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// tlsConfigFromOptions builds a TLS configuration from the probe options.
+// The server certificate is validated against the system certificates and
+// the given server name by default, unless a custom CA, a server name
+// override or the insecure mode is configured.
+func tlsConfigFromOptions(opts Options, serverName string) (*tls.Config, error) {
+	cfg := &tls.Config{ServerName: serverName}
+
+	if v := opts.Params.Get("server_name"); v != "" {
+		cfg.ServerName = v
+	}
+
+	if v := opts.Params.Get("min_tls_version"); v != "" {
+		version, ok := tlsVersions[v]
+		if !ok {
+			return nil, fmt.Errorf("invalid min_tls_version %q: must be one of 1.0, 1.1, 1.2 or 1.3", v)
+		}
+		cfg.MinVersion = version
+	}
+
+	if v := opts.Params.Get("insecure"); v != "" {
+		insecure, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for insecure: %q", v)
+		}
+		cfg.InsecureSkipVerify = insecure
+	}
+
+	// A custom CA replaces the system certificates, so only certificates
+	// signed by it are trusted.
+	caFile, caDir := opts.Params.Get("ca_file"), opts.Params.Get("ca_dir")
+	if caFile != "" || caDir != "" {
+		pool := x509.NewCertPool()
+
+		if caFile != "" {
+			if err := appendCertsFromFile(pool, caFile); err != nil {
+				return nil, err
+			}
+		}
+
+		if caDir != "" {
+			if err := appendCertsFromDir(pool, caDir); err != nil {
+				return nil, err
+			}
+		}
+
+		cfg.RootCAs = pool
+	}
+
+	certFile, keyFile := opts.Params.Get("cert_file"), opts.Params.Get("key_file")
+	if (certFile == "") != (keyFile == "") {
+		return nil, fmt.Errorf("both cert_file and key_file must be provided for client certificates")
+	}
+
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// appendCertsFromFile adds the PEM-encoded certificates in the file to the
+// pool, failing if the file contains none.
+func appendCertsFromFile(pool *x509.CertPool, path string) error {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read CA file: %w", err)
+	}
+
+	if !pool.AppendCertsFromPEM(pem) {
+		return fmt.Errorf("no valid PEM certificates found in CA file %q", path)
+	}
+
+	return nil
+}
+
+// appendCertsFromDir adds the PEM-encoded certificates from every file in
+// the directory to the pool, failing if none of them contains any.
+func appendCertsFromDir(pool *x509.CertPool, dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("unable to read CA directory: %w", err)
+	}
+
+	found := false
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		pem, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return fmt.Errorf("unable to read CA file: %w", err)
+		}
+
+		if pool.AppendCertsFromPEM(pem) {
+			found = true
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("no valid PEM certificates found in CA directory %q", dir)
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+package probes
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCertFiles writes the certificate and its private key as PEM files in
+// the given directory, returning their paths.
+func writeCertFiles(t *testing.T, dir string, cert tls.Certificate) (string, string) {
+	t.Helper()
+
+	certPath := filepath.Join(dir, "cert.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]})
+	if err := os.WriteFile(certPath, certPEM, 0o600); err != nil {
+		t.Fatalf("unable to write certificate: %v", err)
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	if err != nil {
+		t.Fatalf("unable to marshal private key: %v", err)
+	}
+
+	keyPath := filepath.Join(dir, "key.pem")
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(keyPath, keyPEM, 0o600); err != nil {
+		t.Fatalf("unable to write private key: %v", err)
+	}
+
+	return certPath, keyPath
+}
+
+func TestTLSConfigFromOptions(t *testing.T) {
+	cert, err := generateSelfSignedCert()
+	if err != nil {
+		t.Fatalf("Failed to generate self-signed certificate: %v", err)
+	}
+
+	dir := t.TempDir()
+	certPath, keyPath := writeCertFiles(t, dir, cert)
+
+	emptyDir := t.TempDir()
+
+	tests := []struct {
+		name       string
+		params     url.Values
+		wantServer string
+		wantRoots  bool
+		wantCerts  int
+		wantErr    bool
+	}{
+		{
+			name:       "Defaults",
+			wantServer: "example.com",
+		},
+		{
+			name:       "Server name override",
+			params:     url.Values{"server_name": {"internal.example.com"}},
+			wantServer: "internal.example.com",
+		},
+		{
+			name:       "CA file",
+			params:     url.Values{"ca_file": {certPath}},
+			wantServer: "example.com",
+			wantRoots:  true,
+		},
+		{
+			name:       "CA directory",
+			params:     url.Values{"ca_dir": {dir}},
+			wantServer: "example.com",
+			wantRoots:  true,
+		},
+		{
+			name:       "Client certificate",
+			params:     url.Values{"cert_file": {certPath}, "key_file": {keyPath}},
+			wantServer: "example.com",
+			wantCerts:  1,
+		},
+		{
+			name:    "Client certificate without key",
+			params:  url.Values{"cert_file": {certPath}},
+			wantErr: true,
+		},
+		{
+			name:    "CA file without certificates",
+			params:  url.Values{"ca_file": {keyPath}},
+			wantErr: true,
+		},
+		{
+			name:    "CA directory without certificates",
+			params:  url.Values{"ca_dir": {emptyDir}},
+			wantErr: true,
+		},
+		{
+			name:    "Missing CA file",
+			params:  url.Values{"ca_file": {filepath.Join(dir, "missing.pem")}},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid TLS version",
+			params:  url.Values{"min_tls_version": {"1.4"}},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid insecure value",
+			params:  url.Values{"insecure": {"sure"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := tlsConfigFromOptions(Options{Params: tt.params}, "example.com")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("tlsConfigFromOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if cfg.ServerName != tt.wantServer {
+				t.Errorf("ServerName = %q, want %q", cfg.ServerName, tt.wantServer)
+			}
+
+			if (cfg.RootCAs != nil) != tt.wantRoots {
+				t.Errorf("RootCAs set = %v, want %v", cfg.RootCAs != nil, tt.wantRoots)
+			}
+
+			if len(cfg.Certificates) != tt.wantCerts {
+				t.Errorf("len(Certificates) = %d, want %d", len(cfg.Certificates), tt.wantCerts)
+			}
+
+			if cfg.InsecureSkipVerify {
+				t.Errorf("InsecureSkipVerify = true, want false")
+			}
+		})
+	}
+}
+
+func TestHTTPSPinger_TLSOptions(t *testing.T) {
+	serverCert, err := generateSelfSignedCert()
+	if err != nil {
+		t.Fatalf("Failed to generate self-signed certificate: %v", err)
+	}
+
+	clientCert, err := generateSelfSignedCert()
+	if err != nil {
+		t.Fatalf("Failed to generate self-signed certificate: %v", err)
+	}
+
+	serverDir, clientDir := t.TempDir(), t.TempDir()
+	caPath, _ := writeCertFiles(t, serverDir, serverCert)
+	clientCertPath, clientKeyPath := writeCertFiles(t, clientDir, clientCert)
+
+	// The server requires the client to present a certificate.
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	ts.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAnyClientCert,
+	}
+	ts.Config.ErrorLog = log.New(io.Discard, "", 0)
+	ts.StartTLS()
+	defer ts.Close()
+
+	tests := []struct {
+		name    string
+		params  url.Values
+		wantErr bool
+	}{
+		{
+			name:    "System certificates only",
+			wantErr: true,
+		},
+		{
+			name:    "Custom CA without client certificate",
+			params:  url.Values{"ca_file": {caPath}},
+			wantErr: true,
+		},
+		{
+			name:   "Custom CA with client certificate",
+			params: url.Values{"ca_file": {caPath}, "cert_file": {clientCertPath}, "key_file": {clientKeyPath}},
+		},
+		{
+			name:   "Insecure with client certificate",
+			params: url.Values{"insecure": {"true"}, "cert_file": {clientCertPath}, "key_file": {clientKeyPath}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pinger := &HTTPSPinger{}
+			if err := pinger.Bootstrap(ts.URL, Options{Params: tt.params}); err != nil {
+				t.Fatalf("HTTPSPinger.Bootstrap() error = %v", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			if err := pinger.Ping(ctx); (err != nil) != tt.wantErr {
+				t.Errorf("HTTPSPinger.Ping() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
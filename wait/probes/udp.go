@@ -1,6 +1,7 @@
 package probes
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"net"
@@ -10,11 +11,16 @@ import (
 
 // UDPPinger is a pinger for UDP connections.
 type UDPPinger struct {
-	Host string
+	Host    string
+	Timeout time.Duration
 }
 
 // Bootstrap sets up the pinger with the URL.
-func (u *UDPPinger) Bootstrap(host string) error {
+func (u *UDPPinger) Bootstrap(host string, opts Options) error {
+	if err := opts.checkParams(); err != nil {
+		return err
+	}
+
 	url, err := url.Parse(host)
 	if err != nil {
 		return fmt.Errorf("failed to parse host %q: %v", host, err)
@@ -29,6 +35,7 @@ func (u *UDPPinger) Bootstrap(host string) error {
 	}
 
 	u.Host = url.Host
+	u.Timeout = opts.attemptTimeout()
 	return nil
 }
 
@@ -36,9 +43,10 @@ func (u *UDPPinger) Bootstrap(host string) error {
 func (u *UDPPinger) Ping(ctx context.Context) error {
 	// For UDP "ping", we can attempt to send a datagram and check for error.
 	// Unlike TCP, we don't get a "connected" state just by dialing.
-	conn, err := net.DialTimeout("udp", u.Host, 1*time.Second)
+	d := net.Dialer{Timeout: cmp.Or(u.Timeout, DefaultAttemptTimeout)}
+	conn, err := d.DialContext(ctx, "udp", u.Host)
 	if err != nil {
-		return err
+		return dialError(err)
 	}
 	defer conn.Close()
 
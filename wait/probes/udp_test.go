@@ -33,7 +33,7 @@ func TestUDPPinger_Bootstrap(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			pinger := &UDPPinger{}
-			if err := pinger.Bootstrap(tt.urlStr); (err != nil) != tt.wantErr {
+			if err := pinger.Bootstrap(tt.urlStr, Options{}); (err != nil) != tt.wantErr {
 				t.Errorf("UDPPinger.Bootstrap() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -0,0 +1,103 @@
+package wait
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// progressTimeFormat is the format of the time of the last attempt in the
+// summary of the hosts still down.
+const progressTimeFormat = "15:04:05.000"
+
+// progress tracks the attempts made against a host, to explain why it
+// didn't reach its expected state once the timeout is reached. It's updated
+// by the goroutine pinging the host and read by the one waiting for all of
+// them, so access is synchronized.
+type progress struct {
+	mu sync.Mutex
+
+	attempts    int
+	lastAttempt time.Time
+	lastSuccess bool
+	lastErr     error
+}
+
+// record saves the outcome of an attempt started at the given time. The
+// error, if any, must already have the credentials of the host masked.
+func (p *progress) record(at time.Time, success bool, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.attempts++
+	p.lastAttempt = at
+	p.lastSuccess = success
+	p.lastErr = err
+}
+
+// describe returns the number of attempts made against the host, when the
+// last one happened and how it went, such as "3 attempts, last at
+// 10:04:05.000: connection refused".
+func (p *progress) describe(h *matchedURLItem) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.attempts == 0 {
+		if len(h.DependsOn) > 0 {
+			return "no attempts made, waiting for its dependencies"
+		}
+
+		return "no attempts made"
+	}
+
+	plural := "s"
+	if p.attempts == 1 {
+		plural = ""
+	}
+
+	var outcome string
+	switch {
+	case p.lastSuccess:
+		outcome = "in the expected state, but not for long enough yet"
+	case p.lastErr != nil:
+		outcome = p.lastErr.Error()
+	case h.Down:
+		outcome = "still responding"
+	}
+
+	return fmt.Sprintf("%d attempt%s, last at %s: %s", p.attempts, plural, p.lastAttempt.Format(progressTimeFormat), outcome)
+}
+
+// timedOut marks the hosts still pending as failed once the global timeout
+// is reached, which is only an error if they were required, and returns the
+// result of the run. The error explains what happened to each of them.
+func (t *tracker) timedOut(timeout time.Duration) error {
+	err := fmt.Errorf("%s %w before all hosts were up, still waiting for:%s", timeout, ErrTimeout, t.pendingSummary())
+	for i, s := range t.status {
+		if s == statusPending {
+			t.finish(i, err)
+		}
+	}
+
+	_, err = t.result()
+	return err
+}
+
+// pendingSummary returns a line per host still pending, with the attempts
+// made against it and how the last one went.
+func (t *tracker) pendingSummary() string {
+	var sb strings.Builder
+	for i, h := range t.hosts {
+		if t.status[i] != statusPending {
+			continue
+		}
+
+		fmt.Fprintf(&sb, "\n  - %q: %s", h.String(), t.progress[i].describe(&h))
+		if h.Optional {
+			sb.WriteString(" (optional)")
+		}
+	}
+
+	return sb.String()
+}
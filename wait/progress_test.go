@@ -0,0 +1,156 @@
+package wait
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressDescribe(t *testing.T) {
+	at := time.Date(2024, 1, 2, 10, 4, 5, 123000000, time.UTC)
+
+	tests := []struct {
+		name     string
+		host     matchedURLItem
+		attempts []error
+		success  bool
+		want     string
+	}{
+		{
+			name: "No attempts",
+			want: "no attempts made",
+		},
+		{
+			name: "Waiting for dependencies",
+			host: matchedURLItem{DependsOn: []string{"db"}},
+			want: "no attempts made, waiting for its dependencies",
+		},
+		{
+			name:     "Single attempt",
+			attempts: []error{errors.New("connection refused")},
+			want:     "1 attempt, last at 10:04:05.123: connection refused",
+		},
+		{
+			name:     "Last error",
+			attempts: []error{errors.New("connection refused"), errors.New("i/o timeout")},
+			want:     "2 attempts, last at 10:04:05.123: i/o timeout",
+		},
+		{
+			name:     "Not stable yet",
+			attempts: []error{errors.New("connection refused"), nil},
+			success:  true,
+			want:     "2 attempts, last at 10:04:05.123: in the expected state, but not for long enough yet",
+		},
+		{
+			name:     "Still responding",
+			host:     matchedURLItem{Down: true},
+			attempts: []error{nil},
+			want:     "1 attempt, last at 10:04:05.123: still responding",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p progress
+			for i, err := range tt.attempts {
+				p.record(at, tt.success && i == len(tt.attempts)-1, err)
+			}
+
+			if got := p.describe(&tt.host); got != tt.want {
+				t.Errorf("describe() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppRunTimeoutSummary(t *testing.T) {
+	pingerRegistry["refused"] = func() Pinger { return &fakePinger{err: errors.New("connection refused")} }
+	defer delete(pingerRegistry, "refused")
+
+	app := &App{
+		Targets: []Target{
+			{URL: "refused://admin:s3cret@db:5432", Name: "db"},
+			{URL: "refused://api:8080", DependsOn: []string{"db"}},
+			{URL: "refused://tracing:9411", Optional: true},
+		},
+		Timeout:  50 * time.Millisecond,
+		Every:    10 * time.Millisecond,
+		Reporter: NewTextReporter(io.Discard, false),
+	}
+
+	err := app.Run()
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Run() error = %v, want ErrTimeout", err)
+	}
+
+	lines := strings.Split(err.Error(), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Run() error has %d lines, want 4:\n%s", len(lines), err)
+	}
+
+	for i, want := range []string{
+		"50ms timeout reached before all hosts were up, still waiting for:",
+		`  - "db": `,
+		`  - "refused://api:8080": no attempts made, waiting for its dependencies`,
+		`  - "refused://tracing:9411": `,
+	} {
+		if !strings.HasPrefix(lines[i], want) {
+			t.Errorf("Run() error line %d = %q, want prefix %q", i, lines[i], want)
+		}
+	}
+
+	for _, i := range []int{1, 3} {
+		if !strings.Contains(lines[i], " attempts, last at ") || !strings.Contains(lines[i], ": connection refused") {
+			t.Errorf("Run() error line %d = %q, want the attempts and the last error", i, lines[i])
+		}
+	}
+
+	if !strings.HasSuffix(lines[3], "(optional)") {
+		t.Errorf("Run() error line 3 = %q, want the host marked as optional", lines[3])
+	}
+
+	if strings.Contains(err.Error(), "s3cret") {
+		t.Errorf("Run() error = %q, want the password masked", err)
+	}
+}
+
+func TestAppRunHostTimeoutSummary(t *testing.T) {
+	pingerRegistry["refused"] = func() Pinger { return &fakePinger{err: errors.New("connection refused")} }
+	defer delete(pingerRegistry, "refused")
+
+	// The host with its own, shorter timeout ends the run, and the error
+	// lists the other host still down as well.
+	app := &App{
+		Targets: []Target{
+			{URL: "refused://short:80", Name: "short", Timeout: 50 * time.Millisecond},
+			{URL: "refused://long:80", Name: "long"},
+		},
+		Timeout:  10 * time.Second,
+		Every:    10 * time.Millisecond,
+		Reporter: NewTextReporter(io.Discard, false),
+	}
+
+	err := app.Run()
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Run() error = %v, want ErrTimeout", err)
+	}
+
+	lines := strings.Split(err.Error(), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Run() error has %d lines, want 2:\n%s", len(lines), err)
+	}
+
+	if want := `50ms timeout reached while waiting for "short" (`; !strings.HasPrefix(lines[0], want) {
+		t.Errorf("Run() error line 0 = %q, want prefix %q", lines[0], want)
+	}
+
+	if want := ", also still waiting for:"; !strings.HasSuffix(lines[0], want) {
+		t.Errorf("Run() error line 0 = %q, want suffix %q", lines[0], want)
+	}
+
+	if want := `  - "long": `; !strings.HasPrefix(lines[1], want) || !strings.Contains(lines[1], ": connection refused") {
+		t.Errorf("Run() error line 1 = %q, want the attempts against %q", lines[1], "long")
+	}
+}
@@ -0,0 +1,265 @@
+package wait
+
+import (
+	"cmp"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// redactedValue replaces credentials in every rendering of a host.
+const redactedValue = "xxxxx"
+
+// sensitiveParams are the query parameters and probe options whose values
+// are masked, compared case-insensitively. Any parameter containing one of
+// sensitiveParamWords is masked too.
+var sensitiveParams = []string{
+	"pass", "passwd", "pwd", "apikey", "api_key", "sslkey", "auth",
+}
+
+// sensitiveParamWords are the words that make any parameter sensitive, such
+// as "sslpassword", "client_secret" or "access_token".
+var sensitiveParamWords = []string{"password", "secret", "token"}
+
+// tokenUserSchemes are the schemes where a username without a password is
+// a token, so it's masked like a password.
+var tokenUserSchemes = []string{"nats"}
+
+// sensitiveHeaders are the request headers, provided with the "header"
+// option, whose values are masked.
+var sensitiveHeaders = []string{
+	"authorization", "proxy-authorization", "cookie", "x-api-key", "x-auth-token",
+}
+
+// isSensitiveParam reports whether the value of the given query parameter
+// or probe option must be masked.
+func isSensitiveParam(key string) bool {
+	key = strings.ToLower(key)
+	if slices.Contains(sensitiveParams, key) {
+		return true
+	}
+
+	for _, word := range sensitiveParamWords {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sensitiveHeader returns the value of a "Name: value" header if the
+// header is sensitive.
+func sensitiveHeader(header string) (string, bool) {
+	name, value, ok := strings.Cut(header, ":")
+	if !ok || !slices.Contains(sensitiveHeaders, strings.ToLower(strings.TrimSpace(name))) {
+		return "", false
+	}
+
+	return strings.TrimSpace(value), true
+}
+
+// RedactURL masks the password in the userinfo and the values of sensitive
+// query parameters and fragment options of a host URL, keeping everything
+// else as provided so the host can still be recognized. It's exported so
+// hosts rendered outside this package, such as in errors about the
+// configuration file, are masked the same way.
+func RedactURL(raw string) string {
+	rest, fragment, hasFragment := strings.Cut(raw, "#")
+	rest, query, hasQuery := strings.Cut(rest, "?")
+
+	// Find the authority, which may not be preceded by a scheme
+	start := 0
+	if i := strings.Index(rest, "://"); i >= 0 {
+		start = i + len("://")
+	}
+
+	authority := rest[start:]
+	if i := strings.Index(authority, "/"); i >= 0 {
+		authority = authority[:i]
+	}
+
+	// Mask the password, if any, keeping the username
+	if at := strings.LastIndex(authority, "@"); at >= 0 {
+		if user, pass, ok := strings.Cut(authority[:at], ":"); ok && pass != "" {
+			rest = rest[:start] + user + ":" + redactedValue + rest[start+at:]
+		} else if !ok && user != "" && isTokenUserScheme(rest[:start]) {
+			rest = rest[:start] + redactedValue + rest[start+at:]
+		}
+	}
+
+	if hasQuery {
+		rest += "?" + redactQuery(query)
+	}
+
+	if hasFragment {
+		rest += "#" + redactQuery(fragment)
+	}
+
+	return rest
+}
+
+// isTokenUserScheme reports whether the scheme of a host URL, given with
+// its "://" separator, is one where a lone username is a token.
+func isTokenUserScheme(scheme string) bool {
+	scheme = strings.TrimSuffix(strings.TrimPrefix(scheme, downPrefix), "://")
+	return slices.Contains(tokenUserSchemes, strings.ToLower(scheme))
+}
+
+// redactQuery masks the values of sensitive parameters in a query string,
+// keeping the order and encoding of the rest.
+func redactQuery(query string) string {
+	parts := strings.Split(query, "&")
+	for i, part := range parts {
+		k, v, ok := strings.Cut(part, "=")
+		if !ok || v == "" {
+			continue
+		}
+
+		key, err := url.QueryUnescape(k)
+		if err != nil {
+			continue
+		}
+
+		if isSensitiveParam(key) {
+			parts[i] = k + "=" + redactedValue
+			continue
+		}
+
+		if key == "header" {
+			header, err := url.QueryUnescape(v)
+			if err != nil {
+				continue
+			}
+
+			if _, ok := sensitiveHeader(header); ok {
+				name, _, _ := strings.Cut(header, ":")
+				parts[i] = k + "=" + url.QueryEscape(name+": "+redactedValue)
+			}
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
+
+// secretsFromURL returns the credentials found in a host URL: the password
+// in the userinfo, both as provided and decoded, and the values of
+// sensitive query parameters.
+func secretsFromURL(raw string) []string {
+	var secrets []string
+
+	rest, query, _ := strings.Cut(raw, "?")
+
+	start := 0
+	if i := strings.Index(rest, "://"); i >= 0 {
+		start = i + len("://")
+	}
+
+	authority := rest[start:]
+	if i := strings.Index(authority, "/"); i >= 0 {
+		authority = authority[:i]
+	}
+
+	if at := strings.LastIndex(authority, "@"); at >= 0 {
+		user, pass, ok := strings.Cut(authority[:at], ":")
+		if !ok && isTokenUserScheme(rest[:start]) {
+			pass, ok = user, true
+		}
+
+		if ok {
+			secrets = append(secrets, pass)
+			if decoded, err := url.PathUnescape(pass); err == nil {
+				secrets = append(secrets, decoded)
+			}
+		}
+	}
+
+	if params, err := url.ParseQuery(query); err == nil {
+		secrets = append(secrets, secretsFromParams(params)...)
+	}
+
+	return secrets
+}
+
+// secretsFromParams returns the values of sensitive query parameters or
+// probe options, including the values of sensitive headers.
+func secretsFromParams(params url.Values) []string {
+	var secrets []string
+
+	for key, values := range params {
+		if isSensitiveParam(key) {
+			secrets = append(secrets, values...)
+			continue
+		}
+
+		if key == "header" {
+			for _, header := range values {
+				if value, ok := sensitiveHeader(header); ok {
+					secrets = append(secrets, value)
+				}
+			}
+		}
+	}
+
+	return secrets
+}
+
+// addSecrets registers credentials to be masked in every rendering of the
+// host. Longer secrets are masked first, so a secret containing another
+// one is fully masked.
+func (u *matchedURLItem) addSecrets(secrets ...string) {
+	for _, s := range secrets {
+		if s != "" && !slices.Contains(u.secrets, s) {
+			u.secrets = append(u.secrets, s)
+		}
+	}
+
+	slices.SortFunc(u.secrets, func(a, b string) int {
+		return cmp.Compare(len(b), len(a))
+	})
+}
+
+// redact masks the credentials of the host found in s, such as an error
+// message from the probe quoting the host URL.
+func (u *matchedURLItem) redact(s string) string {
+	if u.Raw != "" {
+		s = strings.ReplaceAll(s, u.Raw, RedactURL(u.Raw))
+	}
+
+	for _, secret := range u.secrets {
+		s = strings.ReplaceAll(s, secret, redactedValue)
+	}
+
+	return s
+}
+
+// redactError masks the credentials of the host in the error message,
+// while keeping the original error available to errors.Is and errors.As.
+func (u *matchedURLItem) redactError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	msg := u.redact(err.Error())
+	if msg == err.Error() {
+		return err
+	}
+
+	return &redactedError{msg: msg, err: err}
+}
+
+// redactedError is an error whose message had credentials masked.
+type redactedError struct {
+	msg string
+	err error
+}
+
+// Error returns the masked message.
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the original error.
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,351 @@
+package wait
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventType is the type of an event emitted while waiting for hosts.
+type EventType string
+
+// Events emitted while waiting for hosts, in the order they usually happen.
+const (
+	// EventStart is emitted once, before any host is pinged.
+	EventStart EventType = "start"
+
+	// EventAttempt is emitted after every attempt against a host.
+	EventAttempt EventType = "attempt"
+
+	// EventUp is emitted once a host awaited to be up responds.
+	EventUp EventType = "up"
+
+	// EventDown is emitted once a host awaited to go down stops responding.
+	EventDown EventType = "down"
+
+	// EventTimeout is emitted when a host doesn't reach the expected state
+	// before its timeout.
+	EventTimeout EventType = "timeout"
+
+	// EventGroup is emitted once per group after all hosts are done, with
+	// the members that satisfied it.
+	EventGroup EventType = "group"
+
+	// EventSummary is emitted once, after all hosts are done.
+	EventSummary EventType = "summary"
+)
+
+// HostInfo describes a host being waited for.
+type HostInfo struct {
+	Host  string `json:"host,omitempty"`
+	Name  string `json:"name,omitempty"`
+	Probe string `json:"probe,omitempty"`
+	Until string `json:"until,omitempty"`
+
+	Optional  bool     `json:"optional,omitempty"`
+	DependsOn []string `json:"depends_on,omitempty"`
+}
+
+// label returns the representation of the host used in verbose output,
+// which includes both the name, if any, and the URL.
+func (h HostInfo) label() string {
+	if h.Name != "" {
+		return h.Name + " (" + h.Host + ")"
+	}
+
+	return h.Host
+}
+
+// display returns the representation of the host used to list it, which is
+// the name if one was provided, or the URL as provided by the user.
+func (h HostInfo) display() string {
+	if h.Name != "" {
+		return h.Name
+	}
+
+	if h.Until == UntilDown {
+		return downPrefix + h.Host
+	}
+
+	return h.Host
+}
+
+// GroupInfo describes a group of hosts and, once the run is done, which of
+// its members reached their expected state.
+type GroupInfo struct {
+	Name      string   `json:"name"`
+	Mode      string   `json:"mode"`
+	Required  int      `json:"required"`
+	Members   []string `json:"members"`
+	Satisfied []string `json:"satisfied"`
+}
+
+// describe returns how many members are required to satisfy the group.
+func (g *GroupInfo) describe() string {
+	switch g.Mode {
+	case GroupAny:
+		return fmt.Sprintf("any of %d members required", len(g.Members))
+	case GroupAtLeast:
+		return fmt.Sprintf("at least %d of %d members required", g.Required, len(g.Members))
+	default:
+		return fmt.Sprintf("all %d members required", len(g.Members))
+	}
+}
+
+// Event is emitted while waiting for hosts. Which fields are set depends on
+// the event type: host events embed the host they refer to, while the
+// start event lists all of them.
+type Event struct {
+	Type EventType `json:"event"`
+	Time time.Time `json:"time"`
+
+	HostInfo
+
+	// Start event.
+	Hosts   []HostInfo    `json:"hosts,omitempty"`
+	Timeout time.Duration `json:"-"`
+	Every   time.Duration `json:"-"`
+
+	// Attempt event.
+	Attempt int           `json:"attempt,omitempty"`
+	Latency time.Duration `json:"-"`
+
+	// Attempt event, only when a host must stay in the expected state for
+	// more than a single attempt: the consecutive attempts in the expected
+	// state and how long it has been in it, along with the requirements.
+	Streak    int           `json:"streak,omitempty"`
+	Threshold int           `json:"threshold,omitempty"`
+	Stable    time.Duration `json:"-"`
+	StableFor time.Duration `json:"-"`
+
+	// Group event.
+	Group *GroupInfo `json:"group,omitempty"`
+
+	// Attempt, group and summary events.
+	Success bool `json:"-"`
+
+	// Up, down, timeout and summary events.
+	Elapsed time.Duration `json:"-"`
+
+	// Summary event: the optional hosts that never reached the expected
+	// state.
+	OptionalDown []string `json:"optional_down,omitempty"`
+
+	// Error, if any, from the attempt or the run.
+	Error string `json:"error,omitempty"`
+}
+
+// Reporter receives the events emitted while waiting for hosts. Events for
+// different hosts are emitted concurrently, so implementations must be safe
+// for concurrent use.
+type Reporter interface {
+	Report(e Event)
+}
+
+// TextReporter prints the events as human-readable text. Attempts and
+// per-host outcomes are only printed in verbose mode.
+type TextReporter struct {
+	w       io.Writer
+	verbose bool
+
+	mu      sync.Mutex
+	hosts   []HostInfo
+	padding int
+}
+
+// NewTextReporter creates a reporter that writes text to w.
+func NewTextReporter(w io.Writer, verbose bool) *TextReporter {
+	return &TextReporter{w: w, verbose: verbose}
+}
+
+// Report prints the event.
+func (r *TextReporter) Report(e Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch e.Type {
+	case EventStart:
+		r.hosts = e.Hosts
+
+		hosts := make([]string, 0, len(e.Hosts))
+		for _, h := range e.Hosts {
+			hosts = append(hosts, `"`+h.display()+`"`)
+			r.padding = max(r.padding, len(h.label()))
+		}
+
+		fmt.Fprintln(
+			r.w,
+			"Waiting for hosts:", strings.Join(hosts, ", "),
+			fmt.Sprintf("(timeout: %s, attempting every %s)", e.Timeout, e.Every),
+		)
+
+	case EventAttempt:
+		// Successful attempts are reported by the up and down events, unless
+		// more than one is required.
+		if e.Success {
+			if e.Threshold > 0 {
+				r.printOnVerbose("> %s %s -- %s", stateLabel(e.Until), r.pad(e.label()), streakMessage(e))
+			}
+			return
+		}
+
+		if e.Until == UntilDown {
+			r.printOnVerbose("> up:   %s -- still responding", r.pad(e.label()))
+			return
+		}
+
+		r.printOnVerbose("> down: %s -- %s", r.pad(e.label()), e.Error)
+
+	case EventUp:
+		r.printOnVerbose("> up:   %s (after %s)", r.pad(e.label()), e.Elapsed)
+
+	case EventDown:
+		r.printOnVerbose("> down: %s (after %s) -- %s", r.pad(e.label()), e.Elapsed, e.Error)
+
+	case EventTimeout:
+		// Only optional hosts are reported, since the timeout of any other
+		// host is reported as the error of the run.
+		if e.Optional {
+			fmt.Fprintf(r.w, "Warning: optional host %q didn't reach the expected state after %s", e.display(), e.Elapsed.Round(time.Millisecond))
+			if e.Error != "" {
+				fmt.Fprintf(r.w, ", last error: %s", e.Error)
+			}
+			fmt.Fprintln(r.w, ".")
+		}
+
+	case EventGroup:
+		if e.Success {
+			fmt.Fprintf(r.w, "Group %q satisfied by %s (%s).\n", e.Group.Name, joinNames(e.Group.Satisfied), e.Group.describe())
+		} else {
+			fmt.Fprintf(r.w, "Group %q not satisfied, reached by %s (%s).\n", e.Group.Name, joinNames(e.Group.Satisfied), e.Group.describe())
+		}
+
+	case EventSummary:
+		if len(e.OptionalDown) > 0 {
+			fmt.Fprintf(r.w, "Warning: optional hosts never reached the expected state: %s.\n", strings.Join(e.OptionalDown, ", "))
+		}
+
+		switch {
+		case e.Success && len(e.OptionalDown) > 0:
+			fmt.Fprintln(r.w, "All required hosts reached their expected state.")
+		case e.Success:
+			fmt.Fprintln(r.w, doneMessage(r.hosts))
+		}
+	}
+}
+
+// stateLabel returns the label of the expected state of a host, padded so
+// both states are aligned.
+func stateLabel(until string) string {
+	if until == UntilDown {
+		return "down:"
+	}
+
+	return "up:  "
+}
+
+// streakMessage describes the progress of a host towards staying in the
+// expected state for long enough.
+func streakMessage(e Event) string {
+	msg := fmt.Sprintf("streak %d/%d", e.Streak, e.Threshold)
+	if e.StableFor > 0 {
+		msg += fmt.Sprintf(", stable for %s/%s", e.Stable.Round(time.Millisecond), e.StableFor)
+	}
+
+	return msg
+}
+
+// printOnVerbose prints the message if the verbose flag is enabled.
+func (r *TextReporter) printOnVerbose(format string, args ...any) {
+	if r.verbose {
+		fmt.Fprintf(r.w, format+"\n", args...)
+	}
+}
+
+// pad pads the string to the configured padding based on the longest host
+// full string URL representation (including protocol).
+func (r *TextReporter) pad(str string) string {
+	format := fmt.Sprintf("%%-%ds", r.padding)
+	return fmt.Sprintf(format, str)
+}
+
+// doneMessage returns the message to print once all hosts have reached
+// their expected state, based on whether they were awaited to go up, down,
+// or a mix of both.
+func doneMessage(hosts []HostInfo) string {
+	var up, down int
+	for _, h := range hosts {
+		if h.Until == UntilDown {
+			down++
+		} else {
+			up++
+		}
+	}
+
+	switch {
+	case up == 0:
+		return "All hosts are down and no longer responding."
+	case down == 0:
+		return "All hosts are up and responding."
+	default:
+		return "All hosts reached their expected state."
+	}
+}
+
+// JSONReporter prints every event as a JSON object in its own line, for
+// machine consumption. Durations are reported in milliseconds.
+type JSONReporter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewJSONReporter creates a reporter that writes newline-delimited JSON
+// to w.
+func NewJSONReporter(w io.Writer) *JSONReporter {
+	return &JSONReporter{enc: json.NewEncoder(w)}
+}
+
+// jsonEvent is the JSON representation of an event, with durations
+// converted to milliseconds.
+type jsonEvent struct {
+	Event
+	Success   *bool   `json:"success,omitempty"`
+	TimeoutMS float64 `json:"timeout_ms,omitempty"`
+	EveryMS   float64 `json:"every_ms,omitempty"`
+	LatencyMS float64 `json:"latency_ms,omitempty"`
+	ElapsedMS float64 `json:"elapsed_ms,omitempty"`
+
+	StableMS    float64 `json:"stable_ms,omitempty"`
+	StableForMS float64 `json:"stable_for_ms,omitempty"`
+}
+
+// Report prints the event.
+func (r *JSONReporter) Report(e Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	je := jsonEvent{
+		Event:     e,
+		TimeoutMS: milliseconds(e.Timeout),
+		EveryMS:   milliseconds(e.Every),
+		LatencyMS: milliseconds(e.Latency),
+		ElapsedMS: milliseconds(e.Elapsed),
+
+		StableMS:    milliseconds(e.Stable),
+		StableForMS: milliseconds(e.StableFor),
+	}
+
+	if e.Type == EventAttempt || e.Type == EventGroup || e.Type == EventSummary {
+		je.Success = &e.Success
+	}
+
+	r.enc.Encode(je)
+}
+
+// milliseconds converts the duration to fractional milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,241 @@
+package wait
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTextReporterStart(t *testing.T) {
+	tests := []struct {
+		name  string
+		hosts []HostInfo
+		want  string
+	}{
+		{
+			name:  "Single URL",
+			hosts: []HostInfo{{Host: "http://example.com", Until: UntilUp}},
+			want:  `Waiting for hosts: "http://example.com" (timeout: 10s, attempting every 1s)` + "\n",
+		},
+		{
+			name: "Multiple URLs",
+			hosts: []HostInfo{
+				{Host: "http://example.com", Until: UntilUp},
+				{Host: "https://example.org", Until: UntilDown},
+				{Host: "tcp://db:5432", Name: "db", Until: UntilUp},
+			},
+			want: `Waiting for hosts: "http://example.com", "!https://example.org", "db" (timeout: 10s, attempting every 1s)` + "\n",
+		},
+		{
+			name:  "No URLs",
+			hosts: []HostInfo{},
+			want:  `Waiting for hosts:  (timeout: 10s, attempting every 1s)` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			r := NewTextReporter(&buf, false)
+			r.Report(Event{Type: EventStart, Hosts: tt.hosts, Timeout: 10 * time.Second, Every: time.Second})
+
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Report() printed %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTextReporterPad(t *testing.T) {
+	r := &TextReporter{padding: 5}
+	if got := r.pad("a"); got != "a    " {
+		t.Errorf("pad() = %q", got)
+	}
+}
+
+func TestTextReporterPrintOnVerbose(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewTextReporter(&buf, true)
+	r.printOnVerbose("msg")
+	if buf.String() != "msg\n" {
+		t.Errorf("unexpected output: %q", buf.String())
+	}
+
+	// now ensure nothing printed when verbose false
+	buf.Reset()
+	r.verbose = false
+	r.printOnVerbose("nope")
+	if buf.Len() != 0 {
+		t.Errorf("expected no output, got %q", buf.String())
+	}
+}
+
+func TestTextReporterVerbose(t *testing.T) {
+	up := HostInfo{Host: "tcp://localhost:80", Probe: "tcp", Until: UntilUp}
+	down := HostInfo{Host: "tcp://localhost:8080", Probe: "tcp", Until: UntilDown}
+
+	var buf bytes.Buffer
+	r := NewTextReporter(&buf, true)
+	r.Report(Event{Type: EventStart, Hosts: []HostInfo{up, down}, Timeout: 10 * time.Second, Every: time.Second})
+	r.Report(Event{Type: EventAttempt, HostInfo: up, Attempt: 1, Error: "connection refused"})
+	r.Report(Event{Type: EventAttempt, HostInfo: down, Attempt: 1, Success: false})
+	r.Report(Event{Type: EventAttempt, HostInfo: up, Attempt: 2, Success: true})
+	r.Report(Event{Type: EventUp, HostInfo: up, Attempt: 2, Success: true, Elapsed: time.Second})
+	r.Report(Event{Type: EventDown, HostInfo: down, Attempt: 2, Success: true, Elapsed: 2 * time.Second, Error: "connection refused"})
+	r.Report(Event{Type: EventSummary, Success: true})
+
+	want := strings.Join([]string{
+		`Waiting for hosts: "tcp://localhost:80", "!tcp://localhost:8080" (timeout: 10s, attempting every 1s)`,
+		`> down: tcp://localhost:80   -- connection refused`,
+		`> up:   tcp://localhost:8080 -- still responding`,
+		`> up:   tcp://localhost:80   (after 1s)`,
+		`> down: tcp://localhost:8080 (after 2s) -- connection refused`,
+		`All hosts reached their expected state.`,
+	}, "\n") + "\n"
+
+	if got := buf.String(); got != want {
+		t.Errorf("Report() printed:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestTextReporterStreak(t *testing.T) {
+	up := HostInfo{Host: "tcp://localhost:80", Probe: "tcp", Until: UntilUp}
+	down := HostInfo{Host: "tcp://localhost:8080", Probe: "tcp", Until: UntilDown}
+
+	var buf bytes.Buffer
+	r := NewTextReporter(&buf, true)
+	r.Report(Event{Type: EventStart, Hosts: []HostInfo{up, down}, Timeout: 10 * time.Second, Every: time.Second})
+	r.Report(Event{Type: EventAttempt, HostInfo: up, Attempt: 1, Success: true, Streak: 1, Threshold: 3})
+	r.Report(Event{Type: EventAttempt, HostInfo: up, Attempt: 2, Error: "connection refused", Threshold: 3})
+	r.Report(Event{Type: EventAttempt, HostInfo: down, Attempt: 1, Success: true, Streak: 2, Threshold: 1, Stable: 1500 * time.Millisecond, StableFor: 5 * time.Second})
+
+	want := strings.Join([]string{
+		`Waiting for hosts: "tcp://localhost:80", "!tcp://localhost:8080" (timeout: 10s, attempting every 1s)`,
+		`> up:   tcp://localhost:80   -- streak 1/3`,
+		`> down: tcp://localhost:80   -- connection refused`,
+		`> down: tcp://localhost:8080 -- streak 2/1, stable for 1.5s/5s`,
+	}, "\n") + "\n"
+
+	if got := buf.String(); got != want {
+		t.Errorf("Report() printed:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestTextReporterGroup(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewTextReporter(&buf, false)
+	r.Report(Event{Type: EventGroup, Success: true, Group: &GroupInfo{Name: "brokers", Mode: GroupAtLeast, Required: 2, Members: []string{"kafka-1", "kafka-2", "kafka-3"}, Satisfied: []string{"kafka-1", "kafka-3"}}})
+	r.Report(Event{Type: EventGroup, Group: &GroupInfo{Name: "database", Mode: GroupAny, Required: 1, Members: []string{"primary", "replica"}, Satisfied: []string{}}})
+	r.Report(Event{Type: EventGroup, Group: &GroupInfo{Name: "backend", Mode: GroupAll, Required: 2, Members: []string{"brokers", "database"}, Satisfied: []string{"brokers"}}})
+
+	want := strings.Join([]string{
+		`Group "brokers" satisfied by kafka-1, kafka-3 (at least 2 of 3 members required).`,
+		`Group "database" not satisfied, reached by none (any of 2 members required).`,
+		`Group "backend" not satisfied, reached by brokers (all 2 members required).`,
+	}, "\n") + "\n"
+
+	if got := buf.String(); got != want {
+		t.Errorf("Report() printed:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestTextReporterOptional(t *testing.T) {
+	api := HostInfo{Host: "tcp://api:80", Probe: "tcp", Until: UntilUp}
+	tracing := HostInfo{Host: "tcp://tracing:9411", Name: "tracing", Probe: "tcp", Until: UntilUp, Optional: true}
+
+	var buf bytes.Buffer
+	r := NewTextReporter(&buf, false)
+	r.Report(Event{Type: EventStart, Hosts: []HostInfo{api, tracing}, Timeout: 10 * time.Second, Every: time.Second})
+	r.Report(Event{Type: EventTimeout, HostInfo: api, Elapsed: 5 * time.Second})
+	r.Report(Event{Type: EventTimeout, HostInfo: tracing, Elapsed: 5 * time.Second, Error: "connection refused"})
+	r.Report(Event{Type: EventSummary, Success: true, OptionalDown: []string{"tracing"}})
+
+	want := strings.Join([]string{
+		`Waiting for hosts: "tcp://api:80", "tracing" (timeout: 10s, attempting every 1s)`,
+		`Warning: optional host "tracing" didn't reach the expected state after 5s, last error: connection refused.`,
+		`Warning: optional hosts never reached the expected state: tracing.`,
+		`All required hosts reached their expected state.`,
+	}, "\n") + "\n"
+
+	if got := buf.String(); got != want {
+		t.Errorf("Report() printed:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDoneMessage(t *testing.T) {
+	tests := []struct {
+		name  string
+		hosts []HostInfo
+		want  string
+	}{
+		{
+			name:  "All up",
+			hosts: []HostInfo{{Until: UntilUp}, {Until: UntilUp}},
+			want:  "All hosts are up and responding.",
+		},
+		{
+			name:  "All down",
+			hosts: []HostInfo{{Until: UntilDown}},
+			want:  "All hosts are down and no longer responding.",
+		},
+		{
+			name:  "Mixed",
+			hosts: []HostInfo{{Until: UntilUp}, {Until: UntilDown}},
+			want:  "All hosts reached their expected state.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := doneMessage(tt.hosts); got != tt.want {
+				t.Errorf("doneMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONReporter(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewJSONReporter(&buf)
+
+	host := HostInfo{Host: "tcp://localhost:80", Name: "web", Probe: "tcp", Until: UntilUp}
+	r.Report(Event{Type: EventStart, Hosts: []HostInfo{host}, Timeout: 10 * time.Second, Every: time.Second})
+	r.Report(Event{Type: EventAttempt, HostInfo: host, Attempt: 1, Latency: 1500 * time.Microsecond, Error: "connection refused"})
+	r.Report(Event{Type: EventSummary, Success: false, Elapsed: 10 * time.Second, Error: errors.New("timeout reached").Error()})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d: %q", len(lines), buf.String())
+	}
+
+	var events []map[string]any
+	for _, line := range lines {
+		var e map[string]any
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", line, err)
+		}
+		events = append(events, e)
+	}
+
+	if events[0]["event"] != "start" || events[0]["timeout_ms"] != 10000.0 || events[0]["every_ms"] != 1000.0 {
+		t.Errorf("unexpected start event: %v", events[0])
+	}
+
+	if _, ok := events[0]["success"]; ok {
+		t.Errorf("start event should not include success: %v", events[0])
+	}
+
+	attempt := events[1]
+	if attempt["event"] != "attempt" || attempt["host"] != "tcp://localhost:80" || attempt["name"] != "web" ||
+		attempt["probe"] != "tcp" || attempt["attempt"] != 1.0 || attempt["latency_ms"] != 1.5 ||
+		attempt["success"] != false || attempt["error"] != "connection refused" {
+		t.Errorf("unexpected attempt event: %v", attempt)
+	}
+
+	if events[2]["event"] != "summary" || events[2]["success"] != false || events[2]["elapsed_ms"] != 10000.0 {
+		t.Errorf("unexpected summary event: %v", events[2])
+	}
+}
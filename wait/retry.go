@@ -0,0 +1,129 @@
+package wait
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Values accepted by App.Retry, defining how long to wait between attempts
+// against a host.
+const (
+	// RetryConstant waits the same time between every attempt.
+	RetryConstant = "constant"
+
+	// RetryLinear increases the wait time by the base interval after every
+	// attempt.
+	RetryLinear = "linear"
+
+	// RetryExponential doubles the wait time after every attempt.
+	RetryExponential = "exponential"
+
+	// RetryFullJitter waits a random time between zero and the exponential
+	// wait time, to spread the attempts of many clients.
+	RetryFullJitter = "full-jitter"
+
+	// RetryDecorrelatedJitter waits a random time between the base interval
+	// and three times the previous wait time.
+	RetryDecorrelatedJitter = "decorrelated-jitter"
+)
+
+// DefaultMaxEvery is the maximum time to wait between attempts for the
+// strategies that increase it, when no maximum is set. Hosts attempted
+// less often than that are capped at their base interval instead.
+const DefaultMaxEvery = 30 * time.Second
+
+// retryStrategies are all the supported retry strategies.
+var retryStrategies = []string{
+	RetryConstant, RetryLinear, RetryExponential, RetryFullJitter, RetryDecorrelatedJitter,
+}
+
+// scheduler computes the time to wait before each attempt against a host,
+// based on the retry strategy, the base interval and the maximum interval,
+// if any.
+type scheduler struct {
+	strategy string
+	base     time.Duration
+	max      time.Duration
+
+	retries int
+	prev    time.Duration
+
+	// int64N returns a random number in [0, n), replaceable in tests.
+	int64N func(n int64) int64
+}
+
+// newScheduler creates a scheduler for the strategy. A zero maxInterval
+// means the wait time is not capped.
+func newScheduler(strategy string, base, maxInterval time.Duration) *scheduler {
+	return &scheduler{
+		strategy: strategy,
+		base:     base,
+		max:      maxInterval,
+		prev:     base,
+		int64N:   rand.Int64N,
+	}
+}
+
+// next returns the time to wait between the last attempt and the next one.
+// The maximum interval only applies to the strategies that increase it.
+func (s *scheduler) next() time.Duration {
+	s.retries++
+	if s.strategy == RetryConstant {
+		return s.base
+	}
+
+	d := s.base
+	switch s.strategy {
+	case RetryLinear:
+		d = s.base * time.Duration(s.retries)
+	case RetryExponential:
+		d = s.exponential()
+	case RetryFullJitter:
+		d = s.random(0, s.exponential())
+	case RetryDecorrelatedJitter:
+		d = s.random(s.base, min(s.prev, math.MaxInt64/3)*3)
+	}
+
+	d = s.capped(d)
+	s.prev = d
+	return d
+}
+
+// exponential returns the base interval doubled once per retry after the
+// first one, stopping once the maximum is reached to avoid overflows.
+func (s *scheduler) exponential() time.Duration {
+	d := s.base
+	for i := 1; i < s.retries && (s.max == 0 || d < s.max); i++ {
+		if d > math.MaxInt64/2 {
+			return math.MaxInt64
+		}
+
+		d *= 2
+	}
+
+	return d
+}
+
+// random returns a random duration in [lo, hi].
+func (s *scheduler) random(lo, hi time.Duration) time.Duration {
+	if hi <= lo {
+		return lo
+	}
+
+	n := int64(hi - lo)
+	if n < math.MaxInt64 {
+		n++
+	}
+
+	return lo + time.Duration(s.int64N(n))
+}
+
+// capped limits the duration to the maximum interval, if any.
+func (s *scheduler) capped(d time.Duration) time.Duration {
+	if s.max > 0 && d > s.max {
+		return s.max
+	}
+
+	return d
+}
@@ -0,0 +1,108 @@
+package wait
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSchedulerNext(t *testing.T) {
+	highest := func(n int64) int64 { return n - 1 }
+	lowest := func(int64) int64 { return 0 }
+
+	tests := []struct {
+		name     string
+		strategy string
+		base     time.Duration
+		max      time.Duration
+		int64N   func(int64) int64
+		want     []time.Duration
+	}{
+		{
+			name:     "Constant",
+			strategy: RetryConstant,
+			base:     time.Second,
+			max:      500 * time.Millisecond,
+			want:     []time.Duration{time.Second, time.Second, time.Second},
+		},
+		{
+			name:     "Linear",
+			strategy: RetryLinear,
+			base:     time.Second,
+			want:     []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 4 * time.Second},
+		},
+		{
+			name:     "Linear with cap",
+			strategy: RetryLinear,
+			base:     time.Second,
+			max:      3 * time.Second,
+			want:     []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second},
+		},
+		{
+			name:     "Exponential with cap",
+			strategy: RetryExponential,
+			base:     time.Second,
+			max:      5 * time.Second,
+			want:     []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second},
+		},
+		{
+			name:     "Full jitter upper bound",
+			strategy: RetryFullJitter,
+			base:     time.Second,
+			max:      5 * time.Second,
+			int64N:   highest,
+			want:     []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second},
+		},
+		{
+			name:     "Full jitter lower bound",
+			strategy: RetryFullJitter,
+			base:     time.Second,
+			int64N:   lowest,
+			want:     []time.Duration{0, 0, 0},
+		},
+		{
+			name:     "Decorrelated jitter upper bound",
+			strategy: RetryDecorrelatedJitter,
+			base:     time.Second,
+			max:      10 * time.Second,
+			int64N:   highest,
+			want:     []time.Duration{3 * time.Second, 9 * time.Second, 10 * time.Second, 10 * time.Second},
+		},
+		{
+			name:     "Decorrelated jitter lower bound",
+			strategy: RetryDecorrelatedJitter,
+			base:     time.Second,
+			int64N:   lowest,
+			want:     []time.Duration{time.Second, time.Second, time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newScheduler(tt.strategy, tt.base, tt.max)
+			if tt.int64N != nil {
+				s.int64N = tt.int64N
+			}
+
+			got := make([]time.Duration, 0, len(tt.want))
+			for range tt.want {
+				got = append(got, s.next())
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedulerNoOverflow(t *testing.T) {
+	for _, strategy := range []string{RetryExponential, RetryFullJitter, RetryDecorrelatedJitter} {
+		s := newScheduler(strategy, time.Hour, 0)
+		for i := 0; i < 200; i++ {
+			if d := s.next(); d < 0 {
+				t.Fatalf("%s: next() = %v after %d retries, want a positive duration", strategy, d, i)
+			}
+		}
+	}
+}
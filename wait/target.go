@@ -0,0 +1,56 @@
+package wait
+
+import (
+	"net/url"
+	"time"
+)
+
+// Target is a host to wait for along with its own settings. Any setting
+// left empty falls back to the global one defined in App.
+type Target struct {
+	// URL is the host to wait for, with the same format accepted by
+	// App.Hosts.
+	URL string
+
+	// Name is an optional name to identify the host in the output.
+	Name string
+
+	// Timeout is the maximum time to wait for this host, measured from
+	// the moment the application starts.
+	Timeout time.Duration
+
+	// Every is the time to wait between each attempt against this host.
+	Every time.Duration
+
+	// AttemptTimeout is the maximum time a single attempt against this
+	// host can take.
+	AttemptTimeout time.Duration
+
+	// DependsOn holds the names of the hosts that must reach their expected
+	// state before this host is pinged.
+	DependsOn []string
+
+	// Optional marks the host as nice-to-have: if it doesn't reach the
+	// expected state before its timeout, a warning is reported instead of
+	// failing the run.
+	Optional bool
+
+	// Retry is the strategy used to compute the time to wait between
+	// attempts against this host.
+	Retry string
+
+	// MaxEvery caps the time to wait between attempts against this host.
+	// If zero, App.MaxEvery is used.
+	MaxEvery time.Duration
+
+	// SuccessThreshold is the number of consecutive attempts in which this
+	// host must reach the expected state before it's considered done.
+	SuccessThreshold int
+
+	// StableFor is the minimum time this host must stay in the expected
+	// state, since the first of its consecutive successful attempts.
+	StableFor time.Duration
+
+	// Options holds the probe-specific options for this host.
+	Options url.Values
+}